@@ -24,10 +24,10 @@ func main() {
 		return
 	}
 
-	p := parser.New()
+	p := parser.NewWithOptions(cfg.ParserOptions())
 	sm := matcher.NewStructMatcher()
-	fm := matcher.NewFieldMatcher()
-	r := resolver.New(resolver.DefaultRules()...)
+	fm := matcher.NewFieldMatcherWithOptions(cfg.MatcherOptions())
+	r := resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...)
 	f := generator.NewGoimportsFormatter()
 	w := generator.NewFileWriter()
 	g := generator.New(f, w)
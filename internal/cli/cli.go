@@ -5,21 +5,61 @@ import (
 	"strings"
 
 	"github.com/spf13/pflag"
+
+	"github.com/seitarof/gen-dto/internal/matcher"
+	"github.com/seitarof/gen-dto/internal/resolver"
 )
 
 // ParseArgs parses command line arguments into Config.
 func ParseArgs(args []string) (*Config, error) {
 	cfg := &Config{}
+	var srcTypesRaw []string
 	var ignoreFieldsRaw string
+	var existingFuncsRaw string
+	var nullStringRaw string
+	var narrowingRaw string
+	var mapFieldsRaw string
+	var preferPathsRaw string
+	var wrappersRaw []string
+	var variantsRaw []string
+	var variantFallbackRaw string
+	var defaultsRaw []string
+	var computedPath string
+	var funcShapeRaw string
+	var collectionsRaw string
+	var paramsRaw []string
 
 	fs := pflag.NewFlagSet("gen-dto", pflag.ContinueOnError)
-	fs.StringVarP(&cfg.SrcType, "src-type", "s", "", "source struct type")
+	fs.StringArrayVarP(&srcTypesRaw, "src-type", "s", nil, "source struct type; several types, comma-separated or repeated, are merged into one destination with the earlier ones winning shared fields")
 	fs.StringVar(&cfg.SrcPath, "src-path", "", "source package path")
 	fs.StringVarP(&cfg.DstType, "dst-type", "d", "", "destination struct type")
 	fs.StringVar(&cfg.DstPath, "dst-path", "", "destination package path")
 	fs.StringVarP(&cfg.Filename, "filename", "o", "", "output file name")
 	fs.StringVar(&ignoreFieldsRaw, "ignore-fields", "", "comma-separated field names to ignore")
+	fs.StringVar(&existingFuncsRaw, "existing-funcs", "", "comma-separated converter names written by hand")
 	fs.StringVar(&cfg.FuncName, "func-name", "", "converter function name for root type")
+	fs.StringVar(&nullStringRaw, "null-string", "empty-is-null", "sql.NullString validity for string values: empty-is-null or always-valid")
+	fs.StringVar(&narrowingRaw, "narrowing", "allow", "lossy numeric casts: allow, warn or check")
+	fs.BoolVar(&cfg.NarrowingClamp, "narrowing-clamp", false, "clamp out-of-range values instead of returning an error in check mode")
+	fs.BoolVar(&cfg.DeepCopy, "deep-copy", false, "clone slices, maps and pointers instead of sharing them")
+	fs.BoolVar(&cfg.MatchPaths, "match-paths", false, "match flat fields to nested ones by concatenated name, e.g. AddressCity and Address.City")
+	fs.StringVar(&mapFieldsRaw, "map-fields", "", "comma-separated explicit field mappings, e.g. Address.City=AddressCity")
+	fs.BoolVar(&cfg.EmbeddedUnits, "embedded-as-unit", false, "convert embedded structs as a whole when the other side has a matching embedded or named field")
+	fs.StringVar(&preferPathsRaw, "prefer-path", "", "comma-separated access paths that win over ambiguous promoted fields, e.g. Audit.CreatedAt")
+	fs.StringArrayVar(&wrappersRaw, "wrapper", nil, "optional value wrapper as TYPE:VALUE:VALID[:CONSTRUCTOR], e.g. example.com/optional.Value:V:Set (repeatable)")
+	fs.BoolVar(&cfg.Unexported, "unexported", false, "include unexported fields the generated package can access")
+	fs.BoolVar(&cfg.Accessors, "accessors", false, "read through GetX() getters and write through SetX(v) setters when no field matches")
+	fs.BoolVar(&cfg.Protobuf, "protobuf", false, "convert well-known protobuf types and oneof variants of protoc-gen-go messages")
+	fs.StringArrayVar(&variantsRaw, "variant", nil, "variant pairs of an interface field as FIELD:SRC=DST,..., e.g. Payment:Card=CardDTO,BankTransfer=BankTransferDTO (repeatable)")
+	fs.StringVar(&variantFallbackRaw, "variant-fallback", "skip", "undeclared variants of interface fields: skip, panic or error")
+	fs.StringArrayVar(&defaultsRaw, "default", nil, `constant defaults of destination fields as [Type.]Field=EXPR,..., e.g. Kind="User",APIVersion=2 (repeatable)`)
+	fs.BoolVar(&cfg.SourceOverridesDefault, "source-overrides-default", false, "convert a matched source field over its default when the source value is non-zero")
+	fs.StringVar(&computedPath, "computed", "", "file of computed destination fields, one [Type.]Field: 'EXPR' per line, where EXPR may read src")
+	fs.StringVar(&funcShapeRaw, "func-shape", "pointer", "converter signatures: pointer (F(src *S) *D), value (F(src S) D), method ((src *S) ToD() *D on types of the output package) or into (F(dst *D, src *S))")
+	fs.StringVar(&collectionsRaw, "collections", "", "comma-separated collection converters generated for every struct pair: slice ([]S -> []D), pointer-slice ([]*S -> []*D) or map (map[K]S -> map[K]D)")
+	fs.BoolVar(&cfg.Patch, "patch", false, "generate ApplyDstPatch(dst *Dst, src *Src) functions that only apply the non-nil fields of src, patching nested structs in place")
+	fs.BoolVar(&cfg.PatchNonZero, "patch-non-zero", false, "with --patch, also skip zero values of non-pointer fields")
+	fs.StringArrayVar(&paramsRaw, "param", nil, `extra parameter of every converter as "NAME TYPE", e.g. "loc *time.Location", passed on to nested converters and readable by hooks and computed fields (repeatable)`)
 	fs.BoolVarP(&cfg.ShowVersion, "version", "v", false, "show version")
 
 	if err := fs.Parse(args); err != nil {
@@ -29,8 +69,13 @@ func ParseArgs(args []string) (*Config, error) {
 		return cfg, nil
 	}
 
-	if strings.TrimSpace(cfg.SrcType) == "" {
-		return nil, fmt.Errorf("--src-type is required")
+	srcTypes, err := parseSrcTypes(srcTypesRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SrcType = srcTypes[0]
+	if len(srcTypes) > 1 {
+		cfg.MergeSrcTypes = srcTypes[1:]
 	}
 	if strings.TrimSpace(cfg.SrcPath) == "" {
 		return nil, fmt.Errorf("--src-path is required")
@@ -46,9 +91,331 @@ func ParseArgs(args []string) (*Config, error) {
 	}
 
 	cfg.IgnoreFields = splitCommaList(ignoreFieldsRaw)
+	cfg.ExistingFuncs = splitCommaList(existingFuncsRaw)
+	cfg.PreferredPaths = splitCommaList(preferPathsRaw)
+
+	mappings, err := parseFieldMappings(mapFieldsRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.FieldMappings = mappings
+
+	wrappers, err := parseWrappers(wrappersRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Wrappers = wrappers
+
+	polymorphic, err := parseVariants(variantsRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Polymorphic = polymorphic
+
+	fallback, err := parseVariantFallback(variantFallbackRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.VariantFallback = fallback
+
+	defaults, err := parseDefaults(defaultsRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Defaults = defaults
+
+	if computedPath != "" {
+		computed, err := readComputedFile(computedPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Computed = computed
+	}
+
+	policy, err := parseNullStringPolicy(nullStringRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.NullString = policy
+
+	narrowing, err := parseNarrowingPolicy(narrowingRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Narrowing = narrowing
+
+	shape, err := parseFuncShape(funcShapeRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.FuncShape = shape
+
+	collections, err := parseCollections(collectionsRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Collections = collections
+
+	params, err := parseParams(paramsRaw)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Params = params
+
+	if cfg.Patch && fs.Changed("func-shape") {
+		return nil, fmt.Errorf("--patch cannot be combined with --func-shape")
+	}
+	if cfg.Patch && len(cfg.Defaults) > 0 {
+		return nil, fmt.Errorf("--patch cannot be combined with --default")
+	}
+	if cfg.PatchNonZero && !cfg.Patch {
+		return nil, fmt.Errorf("--patch-non-zero requires --patch")
+	}
+	if len(cfg.MergeSrcTypes) > 0 && cfg.Patch {
+		return nil, fmt.Errorf("--patch cannot be combined with several --src-type")
+	}
+	// Values hold one source and methods have one receiver, so merged
+	// converters take pointers.
+	if len(cfg.MergeSrcTypes) > 0 && (cfg.FuncShape == resolver.FuncShapeValue || cfg.FuncShape == resolver.FuncShapeMethod) {
+		return nil, fmt.Errorf("--func-shape %s cannot merge several --src-type; use pointer or into", funcShapeRaw)
+	}
 	return cfg, nil
 }
 
+// parseSrcTypes splits the --src-type entries, keeping the commas of type
+// argument lists such as Pair[string,User].
+func parseSrcTypes(raw []string) ([]string, error) {
+	var types []string
+	seen := map[string]bool{}
+	for _, spec := range raw {
+		for _, name := range splitExprList(spec) {
+			key := strings.ReplaceAll(name, " ", "")
+			if seen[key] {
+				return nil, fmt.Errorf("--src-type %s is given twice", name)
+			}
+			seen[key] = true
+			types = append(types, name)
+		}
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("--src-type is required")
+	}
+	return types, nil
+}
+
+func parseFieldMappings(raw string) ([]matcher.PathMapping, error) {
+	items := splitCommaList(raw)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	mappings := make([]matcher.PathMapping, 0, len(items))
+	for _, item := range items {
+		from, to, ok := strings.Cut(item, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("--map-fields entries must look like Path=Path, got %q", item)
+		}
+		mappings = append(mappings, matcher.PathMapping{From: from, To: to})
+	}
+	return mappings, nil
+}
+
+func parseWrappers(raw []string) ([]resolver.Wrapper, error) {
+	wrappers := make([]resolver.Wrapper, 0, len(raw))
+	for _, spec := range raw {
+		parts := strings.Split(strings.TrimSpace(spec), ":")
+		if len(parts) < 3 || len(parts) > 4 {
+			return nil, fmt.Errorf("--wrapper must look like TYPE:VALUE:VALID[:CONSTRUCTOR], got %q", spec)
+		}
+		w := resolver.Wrapper{
+			Type:  strings.TrimSpace(parts[0]),
+			Value: strings.TrimSpace(parts[1]),
+			Valid: strings.TrimSpace(parts[2]),
+		}
+		if len(parts) == 4 {
+			w.Constructor = strings.TrimSpace(parts[3])
+		}
+		if err := w.Validate(); err != nil {
+			return nil, fmt.Errorf("--wrapper %q: %w", spec, err)
+		}
+		wrappers = append(wrappers, w)
+	}
+	return wrappers, nil
+}
+
+func parseVariants(raw []string) ([]resolver.Polymorphic, error) {
+	fields := make([]resolver.Polymorphic, 0, len(raw))
+	for _, spec := range raw {
+		field, pairs, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok {
+			return nil, fmt.Errorf("--variant must look like FIELD:SRC=DST,..., got %q", spec)
+		}
+		p := resolver.Polymorphic{Field: strings.TrimSpace(field)}
+		for _, item := range splitCommaList(pairs) {
+			src, dst, ok := strings.Cut(item, "=")
+			if !ok {
+				return nil, fmt.Errorf("--variant pairs must look like SRC=DST, got %q", item)
+			}
+			p.Variants = append(p.Variants, resolver.Variant{Src: strings.TrimSpace(src), Dst: strings.TrimSpace(dst)})
+		}
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("--variant %q: %w", spec, err)
+		}
+		fields = append(fields, p)
+	}
+	return fields, nil
+}
+
+func parseVariantFallback(raw string) (resolver.VariantFallback, error) {
+	switch strings.TrimSpace(raw) {
+	case "", "skip":
+		return resolver.VariantFallbackSkip, nil
+	case "panic":
+		return resolver.VariantFallbackPanic, nil
+	case "error":
+		return resolver.VariantFallbackError, nil
+	default:
+		return 0, fmt.Errorf("--variant-fallback must be skip, panic or error, got %q", raw)
+	}
+}
+
+func parseFuncShape(raw string) (resolver.FuncShape, error) {
+	switch strings.TrimSpace(raw) {
+	case "", "pointer":
+		return resolver.FuncShapePointer, nil
+	case "value":
+		return resolver.FuncShapeValue, nil
+	case "method":
+		return resolver.FuncShapeMethod, nil
+	case "into":
+		return resolver.FuncShapeInto, nil
+	default:
+		return 0, fmt.Errorf("--func-shape must be pointer, value, method or into, got %q", raw)
+	}
+}
+
+func parseParams(raw []string) ([]resolver.Param, error) {
+	params := make([]resolver.Param, 0, len(raw))
+	seen := map[string]bool{}
+	for _, spec := range raw {
+		name, typ, ok := strings.Cut(strings.TrimSpace(spec), " ")
+		p := resolver.Param{Name: name, Type: strings.TrimSpace(typ)}
+		if !ok || p.Type == "" {
+			return nil, fmt.Errorf(`--param must look like "NAME TYPE", got %q`, spec)
+		}
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("--param %q: %w", spec, err)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("--param %s is given twice", p.Name)
+		}
+		seen[p.Name] = true
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+func parseCollections(raw string) ([]resolver.CollectionKind, error) {
+	var kinds []resolver.CollectionKind
+	for _, item := range splitCommaList(raw) {
+		switch item {
+		case "slice":
+			kinds = append(kinds, resolver.CollectionSlice)
+		case "pointer-slice":
+			kinds = append(kinds, resolver.CollectionPointerSlice)
+		case "map":
+			kinds = append(kinds, resolver.CollectionMap)
+		default:
+			return nil, fmt.Errorf("--collections entries must be slice, pointer-slice or map, got %q", item)
+		}
+	}
+	return kinds, nil
+}
+
+func parseDefaults(raw []string) ([]FieldDefault, error) {
+	var defaults []FieldDefault
+	for _, spec := range raw {
+		for _, item := range splitExprList(spec) {
+			target, expr, ok := strings.Cut(item, "=")
+			target, expr = strings.TrimSpace(target), strings.TrimSpace(expr)
+			if !ok || target == "" || expr == "" {
+				return nil, fmt.Errorf("--default entries must look like [Type.]Field=EXPR, got %q", item)
+			}
+			d := FieldDefault{Field: target, Expr: expr}
+			if i := strings.LastIndex(target, "."); i >= 0 {
+				d.Struct, d.Field = target[:i], target[i+1:]
+			}
+			defaults = append(defaults, d)
+		}
+	}
+	return defaults, nil
+}
+
+// splitExprList splits a comma-separated list of Go expressions, ignoring
+// commas inside literals and brackets.
+func splitExprList(raw string) []string {
+	var (
+		out     []string
+		depth   int
+		quote   rune
+		escaped bool
+		start   int
+	)
+	for i, r := range raw {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote != '`' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			out = appendTrimmed(out, raw[start:i])
+			start = i + 1
+		}
+	}
+	return appendTrimmed(out, raw[start:])
+}
+
+func appendTrimmed(items []string, item string) []string {
+	if item = strings.TrimSpace(item); item != "" {
+		items = append(items, item)
+	}
+	return items
+}
+
+func parseNarrowingPolicy(raw string) (resolver.NarrowingPolicy, error) {
+	switch strings.TrimSpace(raw) {
+	case "", "allow":
+		return resolver.NarrowingAllow, nil
+	case "warn":
+		return resolver.NarrowingWarn, nil
+	case "check":
+		return resolver.NarrowingCheck, nil
+	default:
+		return 0, fmt.Errorf("--narrowing must be allow, warn or check, got %q", raw)
+	}
+}
+
+func parseNullStringPolicy(raw string) (resolver.NullStringPolicy, error) {
+	switch strings.TrimSpace(raw) {
+	case "", "empty-is-null":
+		return resolver.NullStringEmptyIsNull, nil
+	case "always-valid":
+		return resolver.NullStringAlwaysValid, nil
+	default:
+		return 0, fmt.Errorf("--null-string must be empty-is-null or always-valid, got %q", raw)
+	}
+}
+
 func splitCommaList(raw string) []string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -1,6 +1,14 @@
 package cli
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seitarof/gen-dto/internal/resolver"
+)
 
 func TestParseArgs_Success(t *testing.T) {
 	cfg, err := ParseArgs([]string{
@@ -20,6 +28,9 @@ func TestParseArgs_Success(t *testing.T) {
 	if len(cfg.IgnoreFields) != 2 {
 		t.Fatalf("expected 2 ignore fields, got %d", len(cfg.IgnoreFields))
 	}
+	if cfg.Unexported {
+		t.Fatal("unexported fields should be excluded by default")
+	}
 }
 
 func TestParseArgs_RequiresFields(t *testing.T) {
@@ -30,3 +41,354 @@ func TestParseArgs_RequiresFields(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestParseArgs_NullStringPolicy(t *testing.T) {
+	args := []string{
+		"--src-type", "User",
+		"--src-path", "./src",
+		"--dst-type", "UserDTO",
+		"--dst-path", "./dst",
+		"--filename", "user_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args, "--null-string", "always-valid"))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if cfg.ResolverOptions().NullString != resolver.NullStringAlwaysValid {
+		t.Fatalf("unexpected null string policy: %v", cfg.NullString)
+	}
+
+	if _, err := ParseArgs(append(args, "--null-string", "sometimes")); err == nil {
+		t.Fatal("expected error for unknown policy, got nil")
+	}
+}
+
+func TestParseArgs_Narrowing(t *testing.T) {
+	args := []string{
+		"--src-type", "User",
+		"--src-path", "./src",
+		"--dst-type", "UserDTO",
+		"--dst-path", "./dst",
+		"--filename", "user_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args, "--narrowing", "check", "--narrowing-clamp"))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	narrowing := cfg.ResolverOptions().Narrowing
+	if narrowing.Policy != resolver.NarrowingCheck || !narrowing.Clamp {
+		t.Fatalf("unexpected narrowing options: %#v", narrowing)
+	}
+	if narrowing.ReturnsError() {
+		t.Fatal("clamping converters should not return errors")
+	}
+
+	if _, err := ParseArgs(append(args, "--narrowing", "deny")); err == nil {
+		t.Fatal("expected error for unknown narrowing policy, got nil")
+	}
+}
+
+func TestParseArgs_FuncShape(t *testing.T) {
+	args := []string{
+		"--src-type", "User",
+		"--src-path", "./src",
+		"--dst-type", "UserDTO",
+		"--dst-path", "./dst",
+		"--filename", "user_gen.go",
+	}
+
+	cfg, err := ParseArgs(args)
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if cfg.FuncShape != resolver.FuncShapePointer {
+		t.Fatalf("unexpected default shape: %v", cfg.FuncShape)
+	}
+
+	cfg, err = ParseArgs(append(args, "--func-shape", "into"))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if shape := cfg.ResolverOptions().Shape; shape != resolver.FuncShapeInto {
+		t.Fatalf("unexpected shape: %v", shape)
+	}
+
+	if _, err := ParseArgs(append(args, "--func-shape", "builder")); err == nil {
+		t.Fatal("expected error for unknown func shape, got nil")
+	}
+}
+
+func TestParseArgs_Collections(t *testing.T) {
+	args := []string{
+		"--src-type", "User",
+		"--src-path", "./src",
+		"--dst-type", "UserDTO",
+		"--dst-path", "./dst",
+		"--filename", "user_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args, "--collections", "slice, map"))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	want := []resolver.CollectionKind{resolver.CollectionSlice, resolver.CollectionMap}
+	if !reflect.DeepEqual(cfg.Collections, want) {
+		t.Fatalf("unexpected collections: %v", cfg.Collections)
+	}
+
+	if _, err := ParseArgs(append(args, "--collections", "array")); err == nil {
+		t.Fatal("expected error for unknown collection kind, got nil")
+	}
+}
+
+func TestParseArgs_Patch(t *testing.T) {
+	args := []string{
+		"--src-type", "UserPatchRequest",
+		"--src-path", "./dto",
+		"--dst-type", "User",
+		"--dst-path", "./model",
+		"--filename", "user_patch_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args, "--patch", "--patch-non-zero"))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if !cfg.Patch || !cfg.PatchNonZero || cfg.ResolverOptions().Shape != resolver.FuncShapePatch {
+		t.Fatalf("unexpected patch options: %#v", cfg)
+	}
+
+	if _, err := ParseArgs(append(args, "--patch", "--func-shape", "value")); err == nil {
+		t.Fatal("expected error for --patch with --func-shape, got nil")
+	}
+	if _, err := ParseArgs(append(args, "--patch-non-zero")); err == nil {
+		t.Fatal("expected error for --patch-non-zero without --patch, got nil")
+	}
+}
+
+func TestParseArgs_MergeSrcTypes(t *testing.T) {
+	args := []string{
+		"--src-type", "User,Profile",
+		"--src-type", "Stats",
+		"--src-path", "./model",
+		"--dst-type", "UserDetailResponse",
+		"--dst-path", "./dto",
+		"--filename", "user_detail_gen.go",
+	}
+
+	cfg, err := ParseArgs(args)
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if cfg.SrcType != "User" || !reflect.DeepEqual(cfg.MergeSrcTypes, []string{"Profile", "Stats"}) {
+		t.Fatalf("unexpected source types: %q, %q", cfg.SrcType, cfg.MergeSrcTypes)
+	}
+
+	if _, err := ParseArgs(append(args, "--src-type", "Profile")); err == nil {
+		t.Fatal("expected error for a repeated source type, got nil")
+	}
+	if _, err := ParseArgs(append(args, "--patch")); err == nil {
+		t.Fatal("expected error for merged sources with --patch, got nil")
+	}
+	for _, shape := range []string{"value", "method"} {
+		if _, err := ParseArgs(append(args, "--func-shape", shape)); err == nil {
+			t.Fatalf("expected error for merged sources with --func-shape %s, got nil", shape)
+		}
+	}
+	if _, err := ParseArgs(append(args, "--func-shape", "into")); err != nil {
+		t.Fatalf("ParseArgs() with --func-shape into error = %v", err)
+	}
+}
+
+func TestParseArgs_Params(t *testing.T) {
+	args := []string{
+		"--src-type", "Event",
+		"--src-path", "./model",
+		"--dst-type", "EventResponse",
+		"--dst-path", "./dto",
+		"--filename", "event_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args, "--param", "loc *time.Location", "--param", "labels map[string]string"))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	want := []resolver.Param{{Name: "loc", Type: "*time.Location"}, {Name: "labels", Type: "map[string]string"}}
+	if !reflect.DeepEqual(cfg.Params, want) {
+		t.Fatalf("unexpected params: %#v", cfg.Params)
+	}
+
+	for _, spec := range []string{"loc", "src *time.Location", "l *time.Location", "loc f()", "loc 1"} {
+		if _, err := ParseArgs(append(args, "--param", spec)); err == nil {
+			t.Fatalf("expected error for --param %q, got nil", spec)
+		}
+	}
+	if _, err := ParseArgs(append(args, "--param", "loc string", "--param", "loc int")); err == nil {
+		t.Fatal("expected error for a repeated parameter, got nil")
+	}
+}
+
+func TestParseArgs_FieldMappings(t *testing.T) {
+	args := []string{
+		"--src-type", "User",
+		"--src-path", "./src",
+		"--dst-type", "UserDTO",
+		"--dst-path", "./dst",
+		"--filename", "user_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args, "--match-paths", "--map-fields", "Address.City=City, Address.Zip=PostalCode"))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	opts := cfg.MatcherOptions()
+	if !opts.MatchPaths || len(opts.Mappings) != 2 {
+		t.Fatalf("unexpected matcher options: %#v", opts)
+	}
+	if opts.Mappings[1].From != "Address.Zip" || opts.Mappings[1].To != "PostalCode" {
+		t.Fatalf("unexpected mapping: %#v", opts.Mappings[1])
+	}
+
+	if _, err := ParseArgs(append(args, "--map-fields", "Address.City")); err == nil {
+		t.Fatal("expected error for mapping without '=', got nil")
+	}
+}
+
+func TestParseArgs_Wrappers(t *testing.T) {
+	args := []string{
+		"--src-type", "User",
+		"--src-path", "./src",
+		"--dst-type", "UserDTO",
+		"--dst-path", "./dst",
+		"--filename", "user_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args,
+		"--wrapper", "example.com/optional.Value:V:Set",
+		"--wrapper", "example.com/opt.Option:Get():IsSome():Some",
+	))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	wrappers := cfg.ResolverOptions().Wrappers
+	if len(wrappers) != 2 {
+		t.Fatalf("expected 2 wrappers, got %#v", wrappers)
+	}
+	want := resolver.Wrapper{Type: "example.com/opt.Option", Value: "Get()", Valid: "IsSome()", Constructor: "Some"}
+	if wrappers[1] != want {
+		t.Fatalf("unexpected wrapper: %#v", wrappers[1])
+	}
+
+	if _, err := ParseArgs(append(args, "--wrapper", "example.com/opt.Option:Get():IsSome()")); err == nil {
+		t.Fatal("expected error for accessor wrapper without constructor, got nil")
+	}
+	if _, err := ParseArgs(append(args, "--wrapper", "Option:V")); err == nil {
+		t.Fatal("expected error for incomplete wrapper, got nil")
+	}
+}
+
+func TestParseArgs_Variants(t *testing.T) {
+	args := []string{
+		"--src-type", "Order",
+		"--src-path", "./src",
+		"--dst-type", "Order",
+		"--dst-path", "./dst",
+		"--filename", "order_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args,
+		"--variant", "Payment:Card=CardDTO,BankTransfer=BankTransferDTO",
+		"--variant-fallback", "error",
+	))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	opts := cfg.ResolverOptions()
+	if len(opts.Polymorphic) != 1 || opts.Polymorphic[0].Field != "Payment" || len(opts.Polymorphic[0].Variants) != 2 {
+		t.Fatalf("unexpected polymorphic fields: %#v", opts.Polymorphic)
+	}
+	if got := opts.Polymorphic[0].Variants[1]; got != (resolver.Variant{Src: "BankTransfer", Dst: "BankTransferDTO"}) {
+		t.Fatalf("unexpected variant: %#v", got)
+	}
+	if !opts.ReturnsError() {
+		t.Fatal("error fallback should make converters return an error")
+	}
+
+	if _, err := ParseArgs(append(args, "--variant", "Payment")); err == nil {
+		t.Fatal("expected error for variant without pairs, got nil")
+	}
+	if _, err := ParseArgs(append(args, "--variant", "Payment:Card")); err == nil {
+		t.Fatal("expected error for incomplete variant pair, got nil")
+	}
+	if _, err := ParseArgs(append(args, "--variant-fallback", "ignore")); err == nil {
+		t.Fatal("expected error for unknown variant fallback, got nil")
+	}
+}
+
+func TestParseArgs_Defaults(t *testing.T) {
+	args := []string{
+		"--src-type", "User",
+		"--src-path", "./src",
+		"--dst-type", "UserResponse",
+		"--dst-path", "./dst",
+		"--filename", "user_gen.go",
+	}
+
+	cfg, err := ParseArgs(append(args,
+		"--default", `Kind="User, v1",APIVersion=2`,
+		"--default", "Meta.Limit=(1 << 4)",
+	))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	want := []FieldDefault{
+		{Field: "Kind", Expr: `"User, v1"`},
+		{Field: "APIVersion", Expr: "2"},
+		{Struct: "Meta", Field: "Limit", Expr: "(1 << 4)"},
+	}
+	if !reflect.DeepEqual(cfg.Defaults, want) {
+		t.Fatalf("unexpected defaults: %#v", cfg.Defaults)
+	}
+
+	if _, err := ParseArgs(append(args, "--default", "Kind")); err == nil {
+		t.Fatal("expected error for default without value, got nil")
+	}
+}
+
+func TestParseArgs_Computed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "computed.yaml")
+	args := []string{
+		"--src-type", "User",
+		"--src-path", "./src",
+		"--dst-type", "UserResponse",
+		"--dst-path", "./dst",
+		"--filename", "user_gen.go",
+		"--computed", path,
+	}
+
+	config := "# derived\nFullName: 'src.FirstName + '' '' + src.LastName'\nUserResponse.Age: src.Age()\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err := ParseArgs(args)
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	want := []ComputedField{
+		{Field: "FullName", Expr: "src.FirstName + ' ' + src.LastName", Pos: path + ":2"},
+		{Struct: "UserResponse", Field: "Age", Expr: "src.Age()", Pos: path + ":3"},
+	}
+	if !reflect.DeepEqual(cfg.Computed, want) {
+		t.Fatalf("unexpected computed fields: %#v", cfg.Computed)
+	}
+
+	if err := os.WriteFile(path, []byte("FullName: src.FirstName +\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := ParseArgs(args); err == nil || !strings.Contains(err.Error(), path+":1: FullName: invalid expression") {
+		t.Fatalf("expected invalid expression error at line 1, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/seitarof/gen-dto/internal/matcher"
+	"github.com/seitarof/gen-dto/internal/parser"
+	"github.com/seitarof/gen-dto/internal/resolver"
+)
+
+// namedCollections pairs named collection roots such as Users and
+// UserResponses, whose elements are converted by the root struct pair.
+type namedCollections struct {
+	src      *parser.CollectionInfo
+	dst      *parser.CollectionInfo
+	funcName string
+}
+
+func (n *namedCollections) reverse() *namedCollections {
+	if n == nil {
+		return nil
+	}
+	return &namedCollections{src: n.dst, dst: n.src}
+}
+
+// collectionRoots resolves named collection roots. When --src-type and
+// --dst-type name collections, it returns a copy of cfg rooted at their
+// element structs.
+func (r *runnerImpl) collectionRoots(cfg *Config) (*Config, *namedCollections, error) {
+	src, err := r.parser.ParseCollection(cfg.SrcPath, cfg.SrcType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse src: %w", err)
+	}
+	dst, err := r.parser.ParseCollection(cfg.DstPath, cfg.DstType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse dst: %w", err)
+	}
+	if src == nil && dst == nil {
+		return cfg, nil, nil
+	}
+	if src == nil || dst == nil || src.Map != dst.Map {
+		return nil, nil, fmt.Errorf("%s and %s must both be structs, slices or maps", cfg.SrcType, cfg.DstType)
+	}
+	if cfg.Patch {
+		return nil, nil, fmt.Errorf("--patch applies to structs, not to %s", src.Name)
+	}
+	if len(cfg.MergeSrcTypes) > 0 {
+		return nil, nil, fmt.Errorf("several --src-type merge into structs, not into %s", dst.Name)
+	}
+	// The generated file belongs to the package of the source elements.
+	if src.ElemPkgPath != src.PkgPath {
+		return nil, nil, fmt.Errorf("elements of %s must be declared in its package", src.Name)
+	}
+
+	elemCfg := *cfg
+	elemCfg.SrcType, elemCfg.SrcPath = src.Elem, src.ElemPkgPath
+	elemCfg.DstType, elemCfg.DstPath = dst.Elem, dst.ElemPkgPath
+	elemCfg.FuncName = ""
+	return &elemCfg, &namedCollections{src: src, dst: dst, funcName: cfg.FuncName}, nil
+}
+
+// collectionPlans returns the collection converters of sp, whose elements
+// conv converts: the named root collections first, then the --collections
+// kinds. Converters written by hand or already named are left out.
+func collectionPlans(ctx planContext, sp matcher.StructPair, conv resolver.Converter, named *namedCollections) []resolver.CollectionPlan {
+	var plans []resolver.CollectionPlan
+	seen := map[string]bool{}
+	if named != nil {
+		plan := resolver.NewNamedCollectionPlan(named.src, named.dst, conv)
+		if named.funcName != "" {
+			plan.FuncName = named.funcName
+		}
+		if !declared(ctx, plan.FuncName) {
+			plans = append(plans, plan)
+		}
+		seen[plan.FuncName] = true
+	}
+	if ctx.cfg.Patch {
+		return plans
+	}
+	for _, kind := range ctx.cfg.Collections {
+		plan := resolver.NewCollectionPlan(kind, sp.Src.PkgPath, sp.Src.Name, sp.Dst.PkgPath, sp.Dst.Name, conv)
+		if seen[plan.FuncName] || declared(ctx, plan.FuncName) {
+			continue
+		}
+		seen[plan.FuncName] = true
+		plans = append(plans, plan)
+	}
+	return plans
+}
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/imports"
+
+	"github.com/seitarof/gen-dto/internal/matcher"
+	"github.com/seitarof/gen-dto/internal/parser"
+	"github.com/seitarof/gen-dto/internal/resolver"
+)
+
+// computedFuncPrefix names the functions that wrap each computed field while
+// the expressions are type-checked.
+const computedFuncPrefix = "genDTOComputed"
+
+type computedEntry struct {
+	field ComputedField
+	pair  matcher.StructPair
+	dst   parser.FieldInfo
+	// sources are the merged sources the expression reads by parameter
+	// name instead of src.
+	sources []*parser.StructInfo
+}
+
+// computedPlans resolves the computed fields of every struct pair into plans,
+// after type-checking all expressions in the output package at once. The
+// plans of a merged destination are keyed by the first of mergeRoots. Errors
+// point at the config line of the offending entry.
+func (r *runnerImpl) computedPlans(
+	cfg *Config,
+	structPairs []matcher.StructPair,
+	mergeRoots []matcher.StructPair,
+	rootDst *parser.StructInfo,
+	outputPkgPath string,
+) (map[matcher.StructPair][]resolver.ConversionPlan, error) {
+	if len(cfg.Computed) == 0 {
+		return nil, nil
+	}
+
+	var sources []*parser.StructInfo
+	if len(mergeRoots) > 0 {
+		structPairs = append(slices.Clone(structPairs), mergeRoots[0])
+		for _, root := range mergeRoots {
+			sources = append(sources, root.Src)
+		}
+	}
+	var entries []computedEntry
+	for _, sp := range structPairs {
+		for _, c := range cfg.Computed {
+			if c.Struct == "" && sp.Dst != rootDst || c.Struct != "" && !sameTypeName(c.Struct, sp.Dst.Name) {
+				continue
+			}
+			field, ok := findFieldByName(sp.Dst.Fields, c.Field)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s has no field %s", c.Pos, sp.Dst.Name, c.Field)
+			}
+			field.TypeStr = renderTypeForOutputPackage(field.Type, outputPkgPath, field.TypeStr)
+			entry := computedEntry{field: c, pair: sp, dst: field}
+			if len(mergeRoots) > 0 && sp == mergeRoots[0] {
+				entry.sources = sources
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	if err := r.checkComputed(cfg, entries, outputPkgPath); err != nil {
+		return nil, err
+	}
+
+	plans := make(map[matcher.StructPair][]resolver.ConversionPlan, len(entries))
+	for _, e := range entries {
+		plans[e.pair] = append(plans[e.pair], resolver.ConversionPlan{
+			SrcField:   parser.FieldInfo{Name: e.field.Field},
+			DstField:   e.dst,
+			Strategy:   resolver.StrategyCustomFunc,
+			Expression: "dst." + e.dst.AccessPath + " = " + e.field.Expr,
+		})
+	}
+	return plans, nil
+}
+
+// checkComputed type-checks each expression as an assignment in a function of
+// the output package taking src, or the merged sources, dst and the --param
+// parameters like the generated converter.
+func (r *runnerImpl) checkComputed(cfg *Config, entries []computedEntry, outputPkgPath string) error {
+	importSet := map[string]string{}
+	var body bytes.Buffer
+	var params string
+	if len(cfg.Params) > 0 {
+		params = ", " + resolver.ParamDecls(cfg.Params)
+	}
+	for i, e := range entries {
+		srcs := "src *" + outputTypeString(e.pair.Src, outputPkgPath, importSet)
+		if e.sources != nil {
+			decls := make([]string, 0, len(e.sources))
+			for _, src := range e.sources {
+				decls = append(decls, resolver.MergeParamName(src.Name)+" *"+outputTypeString(src, outputPkgPath, importSet))
+			}
+			srcs = strings.Join(decls, ", ")
+		}
+		dstType := outputTypeString(e.pair.Dst, outputPkgPath, importSet)
+		fmt.Fprintf(&body, "\nfunc %s%d(%s, dst *%s%s) {\n\tdst.%s = %s\n}\n",
+			computedFuncPrefix, i, srcs, dstType, params, e.dst.AccessPath, e.field.Expr)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "package %s\n", outputPkgName(entries, outputPkgPath))
+	paths := make([]string, 0, len(importSet))
+	for path := range importSet {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&src, "\nimport %s\n", parser.ImportSpec(path, importSet[path]))
+	}
+	src.Write(body.Bytes())
+
+	processed, err := imports.Process(cfg.OutputFilename(), src.Bytes(), nil)
+	if err != nil {
+		return fmt.Errorf("check computed fields: %w", err)
+	}
+	errs, err := r.parser.CheckSource(outputPkgPath, processed)
+	if err != nil {
+		return fmt.Errorf("check computed fields: %w", err)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	lines := computedFuncLines(processed)
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		i := entryAt(lines, e.Line)
+		if i < 0 {
+			msgs = append(msgs, "computed fields: "+e.Msg)
+			continue
+		}
+		c := entries[i].field
+		msgs = append(msgs, fmt.Sprintf("%s: %s: %s", c.Pos, c.Field, e.Msg))
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+}
+
+// computedFuncLines returns the first line of each wrapping function by index.
+func computedFuncLines(src []byte) map[int]int {
+	lines := map[int]int{}
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return lines
+	}
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		var i int
+		if _, err := fmt.Sscanf(fd.Name.Name, computedFuncPrefix+"%d", &i); err == nil {
+			lines[i] = fset.Position(fd.Pos()).Line
+		}
+	}
+	return lines
+}
+
+// entryAt returns the index of the function enclosing line, or -1.
+func entryAt(lines map[int]int, line int) int {
+	best, bestLine := -1, 0
+	for i, start := range lines {
+		if start <= line && start > bestLine {
+			best, bestLine = i, start
+		}
+	}
+	return best
+}
+
+func outputPkgName(entries []computedEntry, outputPkgPath string) string {
+	for _, e := range entries {
+		for _, info := range []*parser.StructInfo{e.pair.Src, e.pair.Dst} {
+			if info.PkgPath == outputPkgPath {
+				return info.PkgName
+			}
+		}
+	}
+	return entries[0].pair.Src.PkgName
+}
+
+// outputTypeString renders info as seen from the output package and records
+// the imports it needs.
+func outputTypeString(info *parser.StructInfo, outputPkgPath string, importSet map[string]string) string {
+	if info.Type == nil {
+		if info.PkgPath == outputPkgPath {
+			return info.Name
+		}
+		importSet[info.PkgPath] = info.PkgName
+		return parser.ImportName(info.PkgName) + "." + info.Name
+	}
+	return types.TypeString(info.Type, func(pkg *types.Package) string {
+		if pkg == nil || pkg.Path() == outputPkgPath {
+			return ""
+		}
+		importSet[pkg.Path()] = pkg.Name()
+		return parser.ImportName(pkg.Name())
+	})
+}
+
+// dropComputedPairs leaves computed destination fields to their expression.
+func dropComputedPairs(pairs []matcher.FieldPair, plans []resolver.ConversionPlan) []matcher.FieldPair {
+	if len(plans) == 0 {
+		return pairs
+	}
+	out := pairs[:0:0]
+	for _, p := range pairs {
+		computed := false
+		for _, plan := range plans {
+			if plan.DstField.AccessPath == p.DstField.AccessPath && p.DstField.Access == parser.FieldAccessField {
+				computed = true
+				break
+			}
+		}
+		if !computed {
+			out = append(out, p)
+		}
+	}
+	return out
+}
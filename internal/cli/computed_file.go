@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"go/parser"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readComputedFile reads computed fields written as `[Type.]Field: EXPR`, one
+// per line. EXPR may be quoted like a YAML scalar; blank lines and lines
+// starting with # are skipped.
+func readComputedFile(path string) ([]ComputedField, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("--computed: %w", err)
+	}
+	defer f.Close()
+
+	var fields []ComputedField
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		pos := fmt.Sprintf("%s:%d", path, line)
+		field, err := parseComputedLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pos, err)
+		}
+		field.Pos = pos
+		fields = append(fields, field)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("--computed: %w", err)
+	}
+	return fields, nil
+}
+
+func parseComputedLine(text string) (ComputedField, error) {
+	target, expr, ok := strings.Cut(text, ":")
+	target, expr = strings.TrimSpace(target), strings.TrimSpace(expr)
+	if !ok || target == "" || expr == "" {
+		return ComputedField{}, fmt.Errorf("computed fields must look like [Type.]Field: EXPR, got %q", text)
+	}
+	expr, err := unquoteScalar(expr)
+	if err != nil {
+		return ComputedField{}, err
+	}
+	if _, err := parser.ParseExpr(expr); err != nil {
+		return ComputedField{}, fmt.Errorf("%s: invalid expression: %w", target, err)
+	}
+
+	field := ComputedField{Field: target, Expr: expr}
+	if i := strings.LastIndex(target, "."); i >= 0 {
+		field.Struct, field.Field = target[:i], target[i+1:]
+	}
+	return field, nil
+}
+
+// unquoteScalar strips YAML single or double quotes from s.
+func unquoteScalar(s string) (string, error) {
+	switch {
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted expression %s: %w", s, err)
+		}
+		return unquoted, nil
+	}
+	return s, nil
+}
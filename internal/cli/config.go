@@ -1,18 +1,130 @@
 package cli
 
+import (
+	"github.com/seitarof/gen-dto/internal/matcher"
+	"github.com/seitarof/gen-dto/internal/parser"
+	"github.com/seitarof/gen-dto/internal/resolver"
+)
+
 // Config stores CLI options for a single generation run.
 type Config struct {
-	SrcType      string
-	SrcPath      string
-	DstType      string
-	DstPath      string
-	Filename     string
-	FuncName     string
-	IgnoreFields []string
-	ShowVersion  bool
+	SrcType string
+	// MergeSrcTypes lists further structs of SrcPath merged with SrcType
+	// into DstType. Fields several sources set come from the earliest.
+	MergeSrcTypes   []string
+	SrcPath         string
+	DstType         string
+	DstPath         string
+	Filename        string
+	FuncName        string
+	IgnoreFields    []string
+	ExistingFuncs   []string
+	NullString      resolver.NullStringPolicy
+	DeepCopy        bool
+	Narrowing       resolver.NarrowingPolicy
+	NarrowingClamp  bool
+	MatchPaths      bool
+	FieldMappings   []matcher.PathMapping
+	EmbeddedUnits   bool
+	PreferredPaths  []string
+	Wrappers        []resolver.Wrapper
+	Unexported      bool
+	Accessors       bool
+	Protobuf        bool
+	Polymorphic     []resolver.Polymorphic
+	VariantFallback resolver.VariantFallback
+	Defaults        []FieldDefault
+	// SourceOverridesDefault converts a matched source field over its
+	// default when the source holds a non-zero value.
+	SourceOverridesDefault bool
+	Computed               []ComputedField
+	FuncShape              resolver.FuncShape
+	// Patch generates ApplyDstPatch(dst, src) functions that only apply the
+	// fields src sets; PatchNonZero also skips zero values.
+	Patch        bool
+	PatchNonZero bool
+	// Collections lists the collection converters generated for every
+	// struct pair.
+	Collections []resolver.CollectionKind
+	// Params are extra parameters of every generated converter.
+	Params      []resolver.Param
+	ShowVersion bool
+}
+
+// FieldDefault assigns the constant Expr to a destination field. Without a
+// Struct it applies to the root destination type.
+type FieldDefault struct {
+	Struct string
+	Field  string
+	Expr   string
+}
+
+// String returns d as written on the command line.
+func (d FieldDefault) String() string {
+	if d.Struct == "" {
+		return d.Field + "=" + d.Expr
+	}
+	return d.Struct + "." + d.Field + "=" + d.Expr
+}
+
+// ComputedField assigns the Go expression Expr, which may read src, to a
+// destination field. Without a Struct it applies to the root destination
+// type. Pos locates the entry in its config file as file:line.
+type ComputedField struct {
+	Struct string
+	Field  string
+	Expr   string
+	Pos    string
+}
+
+func (c *Config) funcShape() resolver.FuncShape {
+	if c.Patch {
+		return resolver.FuncShapePatch
+	}
+	return c.FuncShape
 }
 
 // OutputFilename returns destination file path for generator layer.
 func (c *Config) OutputFilename() string {
 	return c.Filename
 }
+
+// MatcherOptions returns field matching options for matcher layer.
+func (c *Config) MatcherOptions() matcher.FieldOptions {
+	return matcher.FieldOptions{
+		MatchPaths:    c.MatchPaths,
+		Mappings:      c.FieldMappings,
+		EmbeddedUnits: c.EmbeddedUnits,
+		Accessors:     c.Accessors,
+		Oneofs:        c.Protobuf,
+	}
+}
+
+// ParserOptions returns struct parsing options for parser layer.
+func (c *Config) ParserOptions() parser.Options {
+	return parser.Options{
+		EmbeddedUnits:  c.EmbeddedUnits,
+		PreferredPaths: c.PreferredPaths,
+		Unexported:     c.Unexported,
+		Accessors:      c.Accessors,
+		Protobuf:       c.Protobuf,
+	}
+}
+
+// ResolverOptions returns built-in rule options for resolver layer.
+func (c *Config) ResolverOptions() resolver.Options {
+	return resolver.Options{
+		NullString:      c.NullString,
+		DeepCopy:        c.DeepCopy,
+		Wrappers:        c.Wrappers,
+		Protobuf:        c.Protobuf,
+		Polymorphic:     c.Polymorphic,
+		VariantFallback: c.VariantFallback,
+		Shape:           c.funcShape(),
+		Params:          c.Params,
+		Narrowing: resolver.Narrowing{
+			Policy: c.Narrowing,
+			Clamp:  c.NarrowingClamp,
+		},
+	}
+}
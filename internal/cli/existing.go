@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"go/types"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+	"github.com/seitarof/gen-dto/internal/resolver"
+)
+
+// handWritten reports whether the converter funcName from srcs to dst is
+// written by hand: listed in --existing-funcs, or declared in the output
+// package with the signature conv would be generated with. Functions of that
+// name with another signature are reported and do not stop the generation.
+// srcs holds one source, or the sources of a merged converter in order.
+func handWritten(ctx planContext, funcName string, srcs []*parser.StructInfo, dst *parser.StructInfo, conv resolver.Converter) bool {
+	if slices.Contains(ctx.cfg.ExistingFuncs, funcName) {
+		return true
+	}
+	fn, ok := ctx.funcs[funcName]
+	if !ok {
+		return false
+	}
+	if fn.Signature != nil && converterSignature(fn.Signature, srcs, dst, conv) {
+		return true
+	}
+	got := "unknown"
+	if fn.Signature != nil {
+		got = types.TypeString(fn.Signature, nil)
+	}
+	log.Printf("gen-dto: warning: %s has signature %s, want %s; it is not reused as a converter",
+		funcName, got, wantSignature(srcs, dst, conv))
+	return false
+}
+
+// declared reports whether funcName is listed in --existing-funcs or
+// declared in the output package, whatever its signature.
+func declared(ctx planContext, funcName string) bool {
+	_, ok := ctx.funcs[funcName]
+	return ok || slices.Contains(ctx.cfg.ExistingFuncs, funcName)
+}
+
+// converterSignature reports whether sig is the signature of conv from srcs
+// to dst.
+func converterSignature(sig *types.Signature, srcs []*parser.StructInfo, dst *parser.StructInfo, conv resolver.Converter) bool {
+	if sig.TypeParams().Len() != 0 || sig.Variadic() {
+		return false
+	}
+	var args, results []func(types.Type) bool
+	pointsToDst := func(t types.Type) bool { return pointsTo(t, dst) }
+	switch conv.Shape {
+	case resolver.FuncShapeValue:
+		args = append(args, func(t types.Type) bool { return isStruct(t, srcs[0]) })
+		results = append(results, func(t types.Type) bool { return isStruct(t, dst) })
+	case resolver.FuncShapeInto, resolver.FuncShapePatch:
+		args = append(args, pointsToDst)
+	default:
+		results = append(results, pointsToDst)
+	}
+	if conv.Shape != resolver.FuncShapeValue {
+		for _, src := range srcs {
+			args = append(args, func(t types.Type) bool { return pointsTo(t, src) })
+		}
+	}
+	if conv.ReturnsError {
+		results = append(results, isErrorType)
+	}
+
+	params, res := sig.Params(), sig.Results()
+	if params.Len() != len(args)+len(conv.Params) || res.Len() != len(results) {
+		return false
+	}
+	for i, ok := range args {
+		if !ok(params.At(i).Type()) {
+			return false
+		}
+	}
+	for i, ok := range results {
+		if !ok(res.At(i).Type()) {
+			return false
+		}
+	}
+	return takesParams(params, len(args), conv.Params)
+}
+
+// wantSignature renders the signature converterSignature expects.
+func wantSignature(srcs []*parser.StructInfo, dst *parser.StructInfo, conv resolver.Converter) string {
+	dstName := dst.PkgName + "." + dst.Name
+	ptr := "*"
+	if conv.Shape == resolver.FuncShapeValue {
+		ptr = ""
+	}
+	srcParams := make([]string, 0, len(srcs))
+	for _, src := range srcs {
+		name := "src"
+		if len(srcs) > 1 {
+			name = resolver.MergeParamName(src.Name)
+		}
+		srcParams = append(srcParams, name+" "+ptr+src.PkgName+"."+src.Name)
+	}
+	params := strings.Join(srcParams, ", ")
+	var result string
+	switch conv.Shape {
+	case resolver.FuncShapeValue:
+		result = dstName
+	case resolver.FuncShapeInto, resolver.FuncShapePatch:
+		params = "dst *" + dstName + ", " + params
+	default:
+		result = "*" + dstName
+	}
+	if decls := resolver.ParamDecls(conv.Params); decls != "" {
+		params += ", " + decls
+	}
+	switch {
+	case conv.ReturnsError && result != "":
+		result = " (" + result + ", error)"
+	case conv.ReturnsError:
+		result = " error"
+	case result != "":
+		result = " " + result
+	}
+	return "func(" + params + ")" + result
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"go/types"
+	"log"
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/matcher"
+	"github.com/seitarof/gen-dto/internal/parser"
+	"github.com/seitarof/gen-dto/internal/resolver"
+)
+
+// Hook name prefixes; the converter name follows, e.g.
+// afterConvertUserToUserResponse.
+const (
+	beforeHookPrefix = "before"
+	afterHookPrefix  = "after"
+)
+
+// conversionHooks returns the hooks declared for the converter funcName among
+// funcs, the functions of the output package.
+func conversionHooks(funcs map[string]parser.FuncInfo, funcName string, sp matcher.StructPair, returnsError bool, extra []resolver.Param) (*resolver.Hook, *resolver.Hook) {
+	return lookupHook(funcs, beforeHookPrefix+funcName, sp, returnsError, extra),
+		lookupHook(funcs, afterHookPrefix+funcName, sp, returnsError, extra)
+}
+
+// lookupHook accepts func(src *Src, dst *Dst), and func(src *Src, dst *Dst)
+// error when converters return errors. Either may also take the extra
+// converter parameters after dst. Other signatures are reported and ignored.
+func lookupHook(funcs map[string]parser.FuncInfo, name string, sp matcher.StructPair, returnsError bool, extra []resolver.Param) *resolver.Hook {
+	fn, ok := funcs[name]
+	if !ok || fn.Signature == nil {
+		return nil
+	}
+	sig := fn.Signature
+	params, results := sig.Params(), sig.Results()
+	withParams := len(extra) > 0 && params.Len() == 2+len(extra) && takesParams(params, 2, extra)
+	valid := sig.TypeParams().Len() == 0 && !sig.Variadic() && (params.Len() == 2 || withParams) &&
+		pointsTo(params.At(0).Type(), sp.Src) && pointsTo(params.At(1).Type(), sp.Dst)
+	switch {
+	case valid && results.Len() == 0:
+		return &resolver.Hook{Name: name, Params: withParams}
+	case valid && results.Len() == 1 && isErrorType(results.At(0).Type()):
+		if returnsError {
+			return &resolver.Hook{Name: name, ReturnsError: true, Params: withParams}
+		}
+		log.Printf("gen-dto: warning: hook %s returns an error but converters do not; it is not called", name)
+		return nil
+	}
+	log.Printf("gen-dto: warning: hook %s has signature %s, want func(src *%s, dst *%s); it is not called",
+		name, types.TypeString(sig, nil), sp.Src.Name, sp.Dst.Name)
+	return nil
+}
+
+// takesParams reports whether the parameters from index from on have the
+// types of extra, written with package names.
+func takesParams(params *types.Tuple, from int, extra []resolver.Param) bool {
+	for i, p := range extra {
+		got := types.TypeString(params.At(from+i).Type(), func(pkg *types.Package) string { return pkg.Name() })
+		if strings.ReplaceAll(got, " ", "") != strings.ReplaceAll(p.Type, " ", "") {
+			return false
+		}
+	}
+	return true
+}
+
+// pointsTo reports whether t is a pointer to the struct described by info.
+// The signature comes from a separate package load, so types are compared by
+// their fully qualified names.
+func pointsTo(t types.Type, info *parser.StructInfo) bool {
+	ptr, ok := t.(*types.Pointer)
+	return ok && isStruct(ptr.Elem(), info)
+}
+
+// isStruct reports whether t is the struct described by info, compared like
+// pointsTo does.
+func isStruct(t types.Type, info *parser.StructInfo) bool {
+	want := info.PkgPath + "." + info.Name
+	if info.Type != nil {
+		want = types.TypeString(info.Type, nil)
+	}
+	return sameTypeName(types.TypeString(t, nil), want)
+}
+
+func isErrorType(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/matcher"
+	"github.com/seitarof/gen-dto/internal/parser"
+	"github.com/seitarof/gen-dto/internal/resolver"
+)
+
+// parseMergeSources parses the structs of --src-type entries after the
+// first, which are merged with it, into srcInfos.
+func (r *runnerImpl) parseMergeSources(cfg *Config, srcInfos []*parser.StructInfo) ([]*parser.StructInfo, error) {
+	for _, name := range cfg.MergeSrcTypes {
+		infos, err := r.parser.ParseRecursive(cfg.SrcPath, name)
+		if err != nil {
+			return nil, fmt.Errorf("parse src %s: %w", name, err)
+		}
+		srcInfos = appendNewInfos(srcInfos, infos)
+	}
+	return srcInfos, nil
+}
+
+// splitMergeRoots takes the pairs of the merged sources with the root
+// destination out of pairs, returning them in precedence order. They are
+// converted together by one merged converter instead.
+func splitMergeRoots(cfg *Config, srcInfos, dstInfos []*parser.StructInfo, pairs []matcher.StructPair) ([]matcher.StructPair, []matcher.StructPair, error) {
+	dst := findStructByName(dstInfos, cfg.DstType)
+	if dst == nil {
+		return nil, nil, fmt.Errorf("no struct %s found", cfg.DstType)
+	}
+	roots := make([]matcher.StructPair, 0, len(cfg.MergeSrcTypes)+1)
+	for _, name := range append([]string{cfg.SrcType}, cfg.MergeSrcTypes...) {
+		src := findStructByName(srcInfos, name)
+		if src == nil {
+			return nil, nil, fmt.Errorf("no struct %s found", name)
+		}
+		roots = append(roots, matcher.StructPair{Src: src, Dst: dst})
+	}
+	rest := pairs[:0:0]
+	for _, p := range pairs {
+		isRoot := p.Dst == dst && slices.ContainsFunc(roots, func(root matcher.StructPair) bool { return root.Src == p.Src })
+		if !isRoot {
+			rest = append(rest, p)
+		}
+	}
+	return rest, roots, nil
+}
+
+// mergePlan plans the converter taking the sources of roots, which share
+// their destination. Each destination field is converted from the first
+// source able to; the other sources matching it are reported and only
+// convert it when every earlier one of them is nil.
+func (r *runnerImpl) mergePlan(ctx planContext, roots, structPairs []matcher.StructPair) (resolver.StructConversionPlan, error) {
+	cfg := ctx.cfg
+	dst := roots[0].Dst
+	defaults, err := collectDefaults(cfg, dst, dst)
+	if err != nil {
+		return resolver.StructConversionPlan{}, err
+	}
+	// Computed fields run after the sources, reading them by parameter
+	// name, so that they are assigned whichever sources are nil.
+	computed := ctx.computed[roots[0]]
+	shape := cfg.FuncShape
+	errorReturn := shape.ErrorReturn(outputTypeString(dst, ctx.outputPkgPath, map[string]string{}))
+
+	resolved := make([][]resolver.ConversionPlan, len(roots))
+	// matches lists the sources converting each destination field, the
+	// first of which owns it.
+	matches := map[string][]int{}
+	for i, root := range roots {
+		resolved[i] = r.fieldPlans(ctx, root, structPairs, defaults, computed, errorReturn)
+		for _, plan := range resolved[i] {
+			if plan.Strategy != resolver.StrategySkip {
+				matches[plan.DstField.AccessPath] = append(matches[plan.DstField.AccessPath], i)
+			}
+		}
+	}
+
+	sources := make([]resolver.MergeSource, 0, len(roots))
+	names := make([]string, 0, len(roots))
+	paramNames := make([]string, 0, len(roots))
+	params := map[string]string{}
+	skipped := map[string]bool{}
+	for i, root := range roots {
+		param := resolver.MergeParamName(root.Src.Name)
+		if prev, ok := params[param]; ok {
+			return resolver.StructConversionPlan{}, fmt.Errorf("--src-type %s and %s would both be passed as %s", prev, root.Src.Name, param)
+		}
+		if slices.ContainsFunc(cfg.Params, func(p resolver.Param) bool { return p.Name == param }) {
+			return resolver.StructConversionPlan{}, fmt.Errorf("--src-type %s is passed as %s, which --param declares too", root.Src.Name, param)
+		}
+		params[param] = root.Src.Name
+		paramNames = append(paramNames, param)
+
+		var plans []resolver.ConversionPlan
+		for _, plan := range resolved[i] {
+			key := plan.DstField.AccessPath
+			sources := matches[key]
+			owned := len(sources) > 0
+			switch {
+			case owned && sources[0] == i:
+				plans = append(plans, plan)
+			case owned && plan.Strategy != resolver.StrategySkip:
+				owner := sources[0]
+				log.Printf(
+					"gen-dto: warning: field %q of %s matches both %s.%s and %s.%s; using %s, listed first in --src-type, unless it is nil",
+					plan.DstField.Name,
+					dst.Name,
+					roots[owner].Src.Name,
+					findPlanSrc(resolved[owner], key),
+					root.Src.Name,
+					plan.SrcField.Name,
+					roots[owner].Src.Name,
+				)
+				var earlier []string
+				for _, j := range sources[:slices.Index(sources, i)] {
+					earlier = append(earlier, paramNames[j])
+				}
+				plans = append(plans, fallbackPlan(plan, earlier))
+			case !owned && !skipped[key]:
+				skipped[key] = true
+				plans = append(plans, plan)
+			}
+		}
+		logSkippedFields(plans)
+		logLossyFields(plans, cfg.ResolverOptions().Narrowing)
+
+		sources = append(sources, resolver.MergeSource{Src: root.Src, Param: param, Plans: plans})
+		names = append(names, root.Src.Name)
+	}
+
+	name := cfg.FuncName
+	if name == "" {
+		name = resolver.MergeFuncName(names, dst.Name)
+	}
+	return resolver.StructConversionPlan{
+		Src:          roots[0].Src,
+		Dst:          dst,
+		FuncName:     name,
+		Defaults:     defaults,
+		ReturnsError: cfg.ResolverOptions().ReturnsError(),
+		Shape:        shape,
+		Sources:      sources,
+		Plans:        computed,
+		Params:       cfg.Params,
+	}, nil
+}
+
+// fallbackPlan guards plan so that it only converts the field when all of
+// the earlier sources matching it are nil.
+func fallbackPlan(plan resolver.ConversionPlan, earlier []string) resolver.ConversionPlan {
+	conds := make([]string, 0, len(earlier))
+	for _, param := range earlier {
+		conds = append(conds, param+" == nil")
+	}
+	plan.Expression = "if " + strings.Join(conds, " && ") + " {\n" + plan.Expression + "\n}"
+	return plan
+}
+
+// findPlanSrc returns the name of the source field converted to the
+// destination field at accessPath.
+func findPlanSrc(plans []resolver.ConversionPlan, accessPath string) string {
+	for _, plan := range plans {
+		if plan.DstField.AccessPath == accessPath && plan.Strategy != resolver.StrategySkip {
+			return plan.SrcField.Name
+		}
+	}
+	return ""
+}
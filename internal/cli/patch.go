@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"github.com/seitarof/gen-dto/internal/matcher"
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// pairPatchFields pairs the structs held by matched fields of paired
+// structs, e.g. AddressPatch with Address, since patch types rarely share
+// their names with the types they patch.
+func (r *runnerImpl) pairPatchFields(cfg *Config, srcInfos, dstInfos []*parser.StructInfo, pairs []matcher.StructPair) []matcher.StructPair {
+	for i := 0; i < len(pairs); i++ {
+		sp := pairs[i]
+		for _, fp := range r.fieldMatch.Match(sp.Src, sp.Dst, cfg.IgnoreFields) {
+			src := heldStruct(srcInfos, fp.SrcField.TypeInfo)
+			dst := heldStruct(dstInfos, fp.DstField.TypeInfo)
+			if src == nil || dst == nil || hasStructPair(pairs, src, dst) {
+				continue
+			}
+			pairs = append(pairs, matcher.StructPair{Src: src, Dst: dst})
+		}
+	}
+	return pairs
+}
+
+// heldStruct returns the parsed struct of a T or *T field.
+func heldStruct(infos []*parser.StructInfo, detail parser.TypeDetail) *parser.StructInfo {
+	if detail.Kind == parser.TypeKindPointer && detail.ElemType != nil {
+		detail = *detail.ElemType
+	}
+	if detail.Kind != parser.TypeKindStruct {
+		return nil
+	}
+	return findStructByPath(infos, detail.PkgPath, detail.StructName)
+}
+
+func hasStructPair(pairs []matcher.StructPair, src, dst *parser.StructInfo) bool {
+	for _, p := range pairs {
+		if p.Src == src && p.Dst == dst {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"go/types"
 	"log"
+	"slices"
+	"strings"
 
 	"github.com/seitarof/gen-dto/internal/generator"
 	"github.com/seitarof/gen-dto/internal/matcher"
@@ -43,6 +45,10 @@ func NewRunner(
 
 // Run executes a single generation cycle.
 func (r *runnerImpl) Run(cfg *Config) error {
+	cfg, rootCollections, err := r.collectionRoots(cfg)
+	if err != nil {
+		return err
+	}
 	srcInfos, err := r.parser.ParseRecursive(cfg.SrcPath, cfg.SrcType)
 	if err != nil {
 		return fmt.Errorf("parse src: %w", err)
@@ -51,57 +57,333 @@ func (r *runnerImpl) Run(cfg *Config) error {
 	if err != nil {
 		return fmt.Errorf("parse dst: %w", err)
 	}
+	outputPkgPath := srcInfos[len(srcInfos)-1].PkgPath
+	if root := findStructByName(srcInfos, cfg.SrcType); root != nil {
+		outputPkgPath = root.PkgPath
+	}
+	srcInfos, err = r.parseMergeSources(cfg, srcInfos)
+	if err != nil {
+		return err
+	}
+
+	srcInfos, dstInfos, variantPairs, err := r.parseVariants(cfg, srcInfos, dstInfos)
+	if err != nil {
+		return fmt.Errorf("parse variants: %w", err)
+	}
+	logAmbiguousFields(srcInfos)
+	logAmbiguousFields(dstInfos)
+	dropInaccessibleFields(srcInfos, outputPkgPath)
+	dropInaccessibleFields(dstInfos, outputPkgPath)
 
 	forwardPairs := r.structMatch.MatchStructs(srcInfos, dstInfos)
 	forwardPairs = ensureRootPair(cfg, srcInfos, dstInfos, forwardPairs)
+	forwardPairs = dedupePairs(append(forwardPairs, variantPairs...))
+	if cfg.Patch {
+		forwardPairs = r.pairPatchFields(cfg, srcInfos, dstInfos, forwardPairs)
+	}
 	if len(forwardPairs) == 0 {
 		return fmt.Errorf("no matching structs found between %q and %q", cfg.SrcType, cfg.DstType)
 	}
+	var mergeRoots []matcher.StructPair
+	if len(cfg.MergeSrcTypes) > 0 {
+		forwardPairs, mergeRoots, err = splitMergeRoots(cfg, srcInfos, dstInfos, forwardPairs)
+		if err != nil {
+			return err
+		}
+	}
 
-	outputPkgPath := srcInfos[len(srcInfos)-1].PkgPath
-	if root := findStructByName(srcInfos, cfg.SrcType); root != nil {
-		outputPkgPath = root.PkgPath
+	if aware, ok := r.resolver.(resolver.OutputPackageAware); ok {
+		aware.SetOutputPackage(outputPkgPath)
+	}
+
+	funcs, err := r.parser.ParseFuncs(outputPkgPath, cfg.OutputFilename())
+	if err != nil {
+		return fmt.Errorf("lookup existing funcs: %w", err)
 	}
 
-	allPlans := make([]resolver.StructConversionPlan, 0, len(forwardPairs)*2)
-	allPlans = r.appendPlans(allPlans, forwardPairs, cfg, cfg.SrcType, cfg.DstType, cfg.FuncName, outputPkgPath)
+	rootDst := findStructByName(dstInfos, cfg.DstType)
+	var reversePairs []matcher.StructPair
+	// A merge is not converted back, so neither are the structs it nests.
+	if len(mergeRoots) == 0 {
+		reversePairs = reverseStructPairs(forwardPairs)
+	}
+	computedPairs := append(slices.Clone(forwardPairs), reversePairs...)
+	computed, err := r.computedPlans(cfg, computedPairs, mergeRoots, rootDst, outputPkgPath)
+	if err != nil {
+		return err
+	}
 
-	reversePairs := reverseStructPairs(forwardPairs)
-	if len(reversePairs) > 0 {
-		allPlans = r.appendPlans(allPlans, reversePairs, cfg, cfg.DstType, cfg.SrcType, "", outputPkgPath)
+	ctx := planContext{
+		cfg:           cfg,
+		outputPkgPath: outputPkgPath,
+		funcs:         funcsByName(funcs),
+		computed:      computed,
+		methods:       map[string]string{},
+	}
+	allPlans := make([]resolver.StructConversionPlan, 0, len(forwardPairs)*2+1)
+	if len(mergeRoots) > 0 {
+		merged, err := r.mergePlan(ctx, mergeRoots, forwardPairs)
+		if err != nil {
+			return err
+		}
+		srcs := make([]*parser.StructInfo, 0, len(merged.Sources))
+		for _, source := range merged.Sources {
+			srcs = append(srcs, source.Src)
+		}
+		conv := resolver.Converter{Name: merged.FuncName, Shape: merged.Shape, ReturnsError: merged.ReturnsError, Params: merged.Params}
+		if !handWritten(ctx, merged.FuncName, srcs, merged.Dst, conv) {
+			allPlans = append(allPlans, merged)
+		}
+	}
+	allPlans, err = r.appendPlans(allPlans, forwardPairs, ctx, cfg.SrcType, cfg.DstType, cfg.FuncName, rootDst, rootCollections)
+	if err != nil {
+		return err
+	}
+
+	// Patches only apply to the destination.
+	if len(reversePairs) > 0 && !cfg.Patch {
+		allPlans, err = r.appendPlans(allPlans, reversePairs, ctx, cfg.DstType, cfg.SrcType, "", nil, rootCollections.reverse())
+		if err != nil {
+			return err
+		}
 	}
 
 	return r.generator.Generate(cfg, allPlans)
 }
 
+// planContext carries what every struct pair of a run is planned with.
+type planContext struct {
+	cfg           *Config
+	outputPkgPath string
+	// funcs holds the functions declared in the output package.
+	funcs    map[string]parser.FuncInfo
+	computed map[matcher.StructPair][]resolver.ConversionPlan
+	// methods records the converter methods declared so far by receiver.
+	methods map[string]string
+}
+
+// appendPlans plans the converters of one direction. rootDst is the
+// destination that unqualified defaults apply to, and rootCollections the
+// named collections converted with the root pair, if any.
 func (r *runnerImpl) appendPlans(
 	dst []resolver.StructConversionPlan,
 	structPairs []matcher.StructPair,
-	cfg *Config,
+	ctx planContext,
 	rootSrcType string,
 	rootDstType string,
 	rootFuncName string,
-	outputPkgPath string,
-) []resolver.StructConversionPlan {
+	rootDst *parser.StructInfo,
+	rootCollections *namedCollections,
+) ([]resolver.StructConversionPlan, error) {
+	cfg, outputPkgPath, computed := ctx.cfg, ctx.outputPkgPath, ctx.computed
+	returnsError := cfg.ResolverOptions().ReturnsError()
+	shape := cfg.ResolverOptions().Shape
 	for _, sp := range structPairs {
-		pairs := r.fieldMatch.Match(sp.Src, sp.Dst, cfg.IgnoreFields)
-		pairs = normalizePairTypeStrings(pairs, outputPkgPath)
-		plans := r.resolver.Resolve(pairs, structPairs)
+		var defaults []resolver.DefaultValue
+		// A patch leaves unset fields alone, defaulted or not.
+		if shape != resolver.FuncShapePatch {
+			var err error
+			defaults, err = collectDefaults(cfg, sp.Dst, rootDst)
+			if err != nil {
+				return nil, err
+			}
+		}
+		conv := resolver.ConverterFor(shape, outputPkgPath, sp.Src.PkgPath, sp.Src.Name, sp.Dst.PkgPath, sp.Dst.Name, returnsError)
+		conv.Params = cfg.Params
+		errorReturn := conv.Shape.ErrorReturn(outputTypeString(sp.Dst, outputPkgPath, map[string]string{}))
+		plans := r.fieldPlans(ctx, sp, structPairs, defaults, computed[sp], errorReturn)
+		plans = append(plans, computed[sp]...)
 		logSkippedFields(plans)
+		logLossyFields(plans, cfg.ResolverOptions().Narrowing)
 
+		// Hooks are named after the converter function even when a method is generated.
 		funcName := resolver.DefaultConverterName(sp.Src.PkgPath, sp.Src.Name, sp.Dst.PkgPath, sp.Dst.Name)
-		if sp.Src.Name == rootSrcType && sp.Dst.Name == rootDstType && rootFuncName != "" {
+		if shape == resolver.FuncShapePatch {
+			funcName = conv.Name
+		}
+		isRoot := sameTypeName(sp.Src.Name, rootSrcType) && sameTypeName(sp.Dst.Name, rootDstType)
+		if isRoot && rootFuncName != "" {
 			funcName = rootFuncName
+			conv.Name = rootFuncName
+		}
+		// Hand-written converters are still called by nested plans, only their generation is skipped.
+		if conv.Shape != resolver.FuncShapeMethod && handWritten(ctx, funcName, []*parser.StructInfo{sp.Src}, sp.Dst, conv) {
+			continue
+		}
+		if conv.Shape == resolver.FuncShapeMethod {
+			if err := declareMethod(ctx.methods, sp, conv.Name); err != nil {
+				return nil, err
+			}
 		}
 
+		var named *namedCollections
+		if isRoot {
+			named = rootCollections
+		}
+		before, after := conversionHooks(ctx.funcs, funcName, sp, returnsError, cfg.Params)
 		dst = append(dst, resolver.StructConversionPlan{
-			Src:      sp.Src,
-			Dst:      sp.Dst,
-			FuncName: funcName,
-			Plans:    plans,
+			Src:          sp.Src,
+			Dst:          sp.Dst,
+			FuncName:     conv.Name,
+			Plans:        plans,
+			Defaults:     defaults,
+			BeforeHook:   before,
+			AfterHook:    after,
+			ReturnsError: returnsError,
+			Shape:        conv.Shape,
+			Collections:  collectionPlans(ctx, sp, conv, named),
+			Params:       cfg.Params,
 		})
 	}
-	return dst
+	return dst, nil
+}
+
+// fieldPlans resolves the matched fields of sp, leaving the fields with a
+// default or a computed value alone unless sources override defaults.
+// errorReturn is how the converter of sp fails, see FuncShape.ErrorReturn.
+func (r *runnerImpl) fieldPlans(
+	ctx planContext,
+	sp matcher.StructPair,
+	structPairs []matcher.StructPair,
+	defaults []resolver.DefaultValue,
+	computed []resolver.ConversionPlan,
+	errorReturn string,
+) []resolver.ConversionPlan {
+	cfg := ctx.cfg
+	pairs := r.fieldMatch.Match(sp.Src, sp.Dst, cfg.IgnoreFields)
+	if !cfg.SourceOverridesDefault {
+		pairs = dropDefaultedPairs(pairs, defaults)
+	}
+	pairs = dropComputedPairs(pairs, computed)
+	pairs = normalizePairTypeStrings(pairs, ctx.outputPkgPath)
+	if aware, ok := r.resolver.(resolver.ErrorReturnAware); ok {
+		aware.SetErrorReturn(errorReturn)
+	}
+	plans := r.resolver.Resolve(pairs, structPairs)
+	if cfg.SourceOverridesDefault {
+		plans = guardDefaultedPlans(plans, defaults)
+	}
+	if cfg.Patch {
+		for i := range plans {
+			plans[i] = resolver.GuardPatch(plans[i], cfg.PatchNonZero)
+		}
+	}
+	return plans
+}
+
+// declareMethod records the converter method name that sp declares on its
+// source, failing when two destinations would give it the same method.
+func declareMethod(methods map[string]string, sp matcher.StructPair, name string) error {
+	key := sp.Src.PkgPath + "." + sp.Src.Name + "." + name
+	if prev, ok := methods[key]; ok {
+		return fmt.Errorf("--func-shape method: %s.%s would declare %s for both %s and %s", sp.Src.PkgName, sp.Src.Name, name, prev, sp.Dst.PkgName+"."+sp.Dst.Name)
+	}
+	methods[key] = sp.Dst.PkgName + "." + sp.Dst.Name
+	return nil
+}
+
+// collectDefaults checks the defaults of info as a destination: --default
+// entries naming it, or the root destination when unqualified, then its
+// dto:"default=..." tags. A --default entry wins over a tag.
+func collectDefaults(cfg *Config, info, rootDst *parser.StructInfo) ([]resolver.DefaultValue, error) {
+	var defaults []resolver.DefaultValue
+	set := map[string]bool{}
+	for _, d := range cfg.Defaults {
+		if d.Struct == "" && info != rootDst || d.Struct != "" && !sameTypeName(d.Struct, info.Name) {
+			continue
+		}
+		field, ok := findFieldByName(info.Fields, d.Field)
+		if !ok {
+			return nil, fmt.Errorf("--default %s: %s has no field %s", d, info.Name, d.Field)
+		}
+		value, err := resolver.CheckDefault(info.Pkg, field, d.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("--default %s: %w", d, err)
+		}
+		defaults = append(defaults, value)
+		set[field.AccessPath] = true
+	}
+	for _, field := range info.Fields {
+		if field.Default == "" || set[field.AccessPath] {
+			continue
+		}
+		value, err := resolver.CheckDefault(info.Pkg, field, field.Default)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s.%s: tag default=%s: %w", info.PkgName, info.Name, field.AccessPath, field.Default, err)
+		}
+		defaults = append(defaults, value)
+	}
+	return defaults, nil
+}
+
+func findFieldByName(fields []parser.FieldInfo, name string) (parser.FieldInfo, bool) {
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return parser.FieldInfo{}, false
+}
+
+// dropDefaultedPairs leaves fields with a default to the default alone.
+func dropDefaultedPairs(pairs []matcher.FieldPair, defaults []resolver.DefaultValue) []matcher.FieldPair {
+	if len(defaults) == 0 {
+		return pairs
+	}
+	out := pairs[:0:0]
+	for _, p := range pairs {
+		if !hasDefault(defaults, p.DstField) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// guardDefaultedPlans converts a source over a default only when present.
+func guardDefaultedPlans(plans []resolver.ConversionPlan, defaults []resolver.DefaultValue) []resolver.ConversionPlan {
+	for i, plan := range plans {
+		if hasDefault(defaults, plan.DstField) {
+			plans[i] = resolver.GuardPresent(plan)
+		}
+	}
+	return plans
+}
+
+func hasDefault(defaults []resolver.DefaultValue, field parser.FieldInfo) bool {
+	for _, d := range defaults {
+		if d.AccessPath == field.AccessPath && field.Access == parser.FieldAccessField {
+			return true
+		}
+	}
+	return false
+}
+
+func funcsByName(funcs []parser.FuncInfo) map[string]parser.FuncInfo {
+	byName := make(map[string]parser.FuncInfo, len(funcs))
+	for _, fn := range funcs {
+		byName[fn.Name] = fn
+	}
+	return byName
+}
+
+// dropInaccessibleFields removes fields whose access path goes through an
+// unexported field of a package other than the output package.
+func dropInaccessibleFields(infos []*parser.StructInfo, outputPkgPath string) {
+	for _, info := range infos {
+		info.Fields = accessibleFields(info.Fields, outputPkgPath)
+		info.PathFields = accessibleFields(info.PathFields, outputPkgPath)
+		info.Embedded = accessibleFields(info.Embedded, outputPkgPath)
+	}
+}
+
+func accessibleFields(fields []parser.FieldInfo, outputPkgPath string) []parser.FieldInfo {
+	out := fields[:0:0]
+	for _, f := range fields {
+		if f.AccessPkg == "" || f.AccessPkg == outputPkgPath {
+			out = append(out, f)
+		}
+	}
+	return out
 }
 
 func normalizePairTypeStrings(pairs []matcher.FieldPair, outputPkgPath string) []matcher.FieldPair {
@@ -112,11 +394,36 @@ func normalizePairTypeStrings(pairs []matcher.FieldPair, outputPkgPath string) [
 	for _, p := range pairs {
 		p.SrcField.TypeStr = renderTypeForOutputPackage(p.SrcField.Type, outputPkgPath, p.SrcField.TypeStr)
 		p.DstField.TypeStr = renderTypeForOutputPackage(p.DstField.Type, outputPkgPath, p.DstField.TypeStr)
+		p.SrcField.PointerHops = normalizeHopTypeStrings(p.SrcField.PointerHops, outputPkgPath)
+		p.DstField.PointerHops = normalizeHopTypeStrings(p.DstField.PointerHops, outputPkgPath)
+		p.SrcField.Oneof = normalizeOneofTypeString(p.SrcField.Oneof, outputPkgPath)
+		p.DstField.Oneof = normalizeOneofTypeString(p.DstField.Oneof, outputPkgPath)
 		out = append(out, p)
 	}
 	return out
 }
 
+func normalizeHopTypeStrings(hops []parser.PointerHop, outputPkgPath string) []parser.PointerHop {
+	if len(hops) == 0 {
+		return hops
+	}
+	out := make([]parser.PointerHop, len(hops))
+	for i, hop := range hops {
+		hop.TypeStr = renderTypeForOutputPackage(hop.Type, outputPkgPath, hop.TypeStr)
+		out[i] = hop
+	}
+	return out
+}
+
+func normalizeOneofTypeString(variant *parser.OneofVariant, outputPkgPath string) *parser.OneofVariant {
+	if variant == nil {
+		return nil
+	}
+	out := *variant
+	out.WrapperStr = renderTypeForOutputPackage(out.Wrapper, outputPkgPath, out.WrapperStr)
+	return &out
+}
+
 func renderTypeForOutputPackage(t types.Type, outputPkgPath string, fallback string) string {
 	if t == nil {
 		return fallback
@@ -128,11 +435,86 @@ func renderTypeForOutputPackage(t types.Type, outputPkgPath string, fallback str
 		if outputPkgPath != "" && pkg.Path() == outputPkgPath {
 			return ""
 		}
-		return pkg.Name()
+		return parser.ImportName(pkg.Name())
 	}
 	return types.TypeString(t, qualifier)
 }
 
+// parseVariants parses the declared variants of polymorphic fields, with the
+// structs they reach, from the packages declaring the fields' interfaces, and
+// pairs each source variant with its destination variant.
+func (r *runnerImpl) parseVariants(
+	cfg *Config,
+	srcInfos []*parser.StructInfo,
+	dstInfos []*parser.StructInfo,
+) ([]*parser.StructInfo, []*parser.StructInfo, []matcher.StructPair, error) {
+	var pairs []matcher.StructPair
+	for _, decl := range cfg.Polymorphic {
+		srcPkg, ok := interfacePkgPath(srcInfos, decl.Field)
+		if !ok {
+			log.Printf("gen-dto: warning: no interface field %s in %s", decl.Field, cfg.SrcType)
+			continue
+		}
+		dstPkg, ok := interfacePkgPath(dstInfos, decl.Field)
+		if !ok {
+			log.Printf("gen-dto: warning: no interface field %s in %s", decl.Field, cfg.DstType)
+			continue
+		}
+		for _, v := range decl.Variants {
+			srcVariant, err := r.parser.ParseRecursive(srcPkg, v.Src)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("variant %s of %s: %w", v.Src, decl.Field, err)
+			}
+			dstVariant, err := r.parser.ParseRecursive(dstPkg, v.Dst)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("variant %s of %s: %w", v.Dst, decl.Field, err)
+			}
+			srcInfos = appendNewInfos(srcInfos, srcVariant)
+			dstInfos = appendNewInfos(dstInfos, dstVariant)
+			pairs = append(pairs, matcher.StructPair{
+				Src: findStructByPath(srcInfos, srcPkg, v.Src),
+				Dst: findStructByPath(dstInfos, dstPkg, v.Dst),
+			})
+		}
+	}
+	return srcInfos, dstInfos, pairs, nil
+}
+
+// interfacePkgPath returns the package declaring the named interface type of
+// the first field called name.
+func interfacePkgPath(infos []*parser.StructInfo, name string) (string, bool) {
+	for _, info := range infos {
+		for _, f := range info.Fields {
+			if !strings.EqualFold(f.Name, name) || f.TypeInfo.Kind != parser.TypeKindInterface {
+				continue
+			}
+			named, ok := types.Unalias(f.Type).(*types.Named)
+			if ok && named.Obj().Pkg() != nil {
+				return named.Obj().Pkg().Path(), true
+			}
+		}
+	}
+	return "", false
+}
+
+func appendNewInfos(infos, more []*parser.StructInfo) []*parser.StructInfo {
+	for _, info := range more {
+		if findStructByPath(infos, info.PkgPath, info.Name) == nil {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+func findStructByPath(infos []*parser.StructInfo, pkgPath, name string) *parser.StructInfo {
+	for _, info := range infos {
+		if info.PkgPath == pkgPath && sameTypeName(info.Name, name) {
+			return info
+		}
+	}
+	return nil
+}
+
 func reverseStructPairs(forwardPairs []matcher.StructPair) []matcher.StructPair {
 	if len(forwardPairs) == 0 {
 		return nil
@@ -196,13 +578,32 @@ func dedupePairs(pairs []matcher.StructPair) []matcher.StructPair {
 
 func findStructByName(infos []*parser.StructInfo, name string) *parser.StructInfo {
 	for _, info := range infos {
-		if info.Name == name {
+		if sameTypeName(info.Name, name) {
 			return info
 		}
 	}
 	return nil
 }
 
+// sameTypeName compares struct names ignoring spacing inside type argument
+// lists, so Pair[string,User] matches Pair[string, User].
+func sameTypeName(a, b string) bool {
+	return strings.ReplaceAll(a, " ", "") == strings.ReplaceAll(b, " ", "")
+}
+
+func logAmbiguousFields(infos []*parser.StructInfo) {
+	for _, info := range infos {
+		for _, field := range info.Ambiguous {
+			log.Printf(
+				"gen-dto: warning: field %q of %s is ambiguous between %s, skipped; pick one with --prefer-path",
+				field.Name,
+				info.Name,
+				strings.Join(field.AccessPaths, " and "),
+			)
+		}
+	}
+}
+
 func logSkippedFields(plans []resolver.ConversionPlan) {
 	for _, plan := range plans {
 		if plan.Strategy != resolver.StrategySkip {
@@ -217,3 +618,32 @@ func logSkippedFields(plans []resolver.ConversionPlan) {
 		)
 	}
 }
+
+// logLossyFields reports lossy numeric casts unless narrowing allows them,
+// which keeps the default policy silent.
+func logLossyFields(plans []resolver.ConversionPlan, narrowing resolver.Narrowing) {
+	var note string
+	switch {
+	case narrowing.Policy == resolver.NarrowingAllow:
+		return
+	case narrowing.Policy == resolver.NarrowingWarn:
+		note = "may lose data"
+	case narrowing.Clamp:
+		note = "clamped to range or rounded"
+	default:
+		note = "range-checked"
+	}
+	for _, plan := range plans {
+		if !plan.Lossy {
+			continue
+		}
+		log.Printf(
+			"gen-dto: warning: field %q (%s) -> %q (%s): narrowing conversion %s",
+			plan.SrcField.Name,
+			plan.SrcField.TypeStr,
+			plan.DstField.Name,
+			plan.DstField.TypeStr,
+			note,
+		)
+	}
+}
@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
+	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"golang.org/x/tools/go/packages"
+
 	"github.com/seitarof/gen-dto/internal/generator"
 	"github.com/seitarof/gen-dto/internal/matcher"
 	"github.com/seitarof/gen-dto/internal/parser"
@@ -39,6 +45,7 @@ func TestRunner_Run_GeneratesBidirectionalConverters(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ReadFile() error = %v", err)
 	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
 	got := string(content)
 
 	checks := []string{
@@ -81,6 +88,7 @@ func TestRunner_Run_SupportsTypeAliasField(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ReadFile() error = %v", err)
 	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
 	got := string(content)
 
 	checks := []string{
@@ -122,12 +130,16 @@ func TestRunner_Run_SupportsNestedAliasStructFieldWithCast(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ReadFile() error = %v", err)
 	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
 	got := string(content)
 
 	checks := []string{
 		"package src",
 		"func ConvertPatientToPatientDTO",
-		"dst.Provider = (dst.PatientProviderType)(src.Provider)",
+		// The package named dst is imported as dstpkg so the dst variable
+		// does not shadow it.
+		`dstpkg "github.com/seitarof/gen-dto/testdata/aliasnested/dst"`,
+		"dst.Provider = (dstpkg.PatientProviderType)(src.Provider)",
 		"func ConvertPatientDTOToPatient",
 		"dst.Provider = (ProviderAlias)(src.Provider)",
 	}
@@ -165,6 +177,7 @@ func TestRunner_Run_RecursesNestedStructsAcrossPackagesInSameModule(t *testing.T
 	if err != nil {
 		t.Fatalf("ReadFile() error = %v", err)
 	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
 	got := string(content)
 
 	checks := []string{
@@ -183,3 +196,1735 @@ func TestRunner_Run_RecursesNestedStructsAcrossPackagesInSameModule(t *testing.T
 		t.Fatalf("recipient conversion should not be skipped\n%s", got)
 	}
 }
+
+func TestRunner_Run_ReusesHandWrittenConverter(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "existing_gen.go")
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	cfg := &Config{
+		SrcType:  "Order",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/existingconv/model",
+		DstType:  "Order",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/existingconv/dto",
+		Filename: out,
+	}
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"func ConvertModelOrderToDtoOrder",
+		"ConvertModelMoneyToDtoMoney(&src.Total)",
+		"func ConvertDtoMoneyToModelMoney",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+
+	if strings.Contains(got, "func ConvertModelMoneyToDtoMoney") {
+		t.Fatalf("hand-written converter should not be generated\n%s", got)
+	}
+}
+
+func TestRunner_Run_ConvertsTextTypesBothWays(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "text_gen.go")
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	cfg := &Config{
+		SrcType:  "Device",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/textconv/model",
+		DstType:  "Device",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/textconv/dto",
+		Filename: out,
+	}
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"if b, err := src.ID.MarshalText(); err == nil {",
+		"dst.IP = src.IP.String()",
+		"if b, err := src.Tag.MarshalText(); err == nil {",
+		"if v, err := ParseDeviceID(src.ID); err == nil {",
+		"if v, err := ParseDeviceID(src.Primary); err == nil {",
+		"if v, err := netip.ParseAddr(src.IP); err == nil {",
+		"if err := v.UnmarshalText([]byte(src.Tag)); err == nil {",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	if strings.Contains(got, "TODO") {
+		t.Fatalf("all text fields should be converted\n%s", got)
+	}
+}
+
+func TestRunner_Run_ReturnsTextParseErrors(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "text_gen.go")
+
+	cfg := &Config{
+		SrcType:   "Device",
+		SrcPath:   "github.com/seitarof/gen-dto/testdata/textconv/model",
+		DstType:   "Device",
+		DstPath:   "github.com/seitarof/gen-dto/testdata/textconv/dto",
+		Filename:  out,
+		Narrowing: resolver.NarrowingCheck,
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"func ConvertDtoDeviceToModelDevice(src *dto.Device) (*Device, error)",
+		"if v, err := ParseDeviceID(src.ID); err != nil {",
+		`return nil, fmt.Errorf("gen-dto: ID: %w", err)`,
+		"if v, err := netip.ParseAddr(src.IP); err != nil {",
+		`return nil, fmt.Errorf("gen-dto: IP: %w", err)`,
+		"if err := v.UnmarshalText([]byte(src.Tag)); err != nil {",
+		`return nil, fmt.Errorf("gen-dto: Tag: %w", err)`,
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+}
+
+func TestRunner_Run_DeepCopiesReferenceTypes(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "deepcopy_gen.go")
+
+	cfg := &Config{
+		SrcType:  "Account",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/deepcopy/model",
+		DstType:  "Account",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/deepcopy/dto",
+		Filename: out,
+		DeepCopy: true,
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"dst.Tags = slices.Clone(src.Tags)",
+		"dst.Scores = maps.Clone(src.Scores)",
+		"dst.Scores[k0] = slices.Clone(dst.Scores[k0])",
+		"dst.Data = slices.Clone(src.Data)",
+		"c0 := *src.Created",
+		"dst.Created = &c0",
+		"c1 := *dst.Aliases[i0]",
+		"v := src.Nick",
+		"c0 := *src.Owner\n\t\tc0.Roles = slices.Clone(c0.Roles)\n",
+		"dst.Plan.Features = slices.Clone(dst.Plan.Features)",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	if strings.Contains(got, "dst.Tags = src.Tags") {
+		t.Fatalf("slices should not be shared in deep-copy mode\n%s", got)
+	}
+
+	testGenerated(t, cfg.SrcPath, out, `package model
+
+import (
+	"testing"
+
+	"github.com/seitarof/gen-dto/testdata/deepcopy/shared"
+)
+
+func TestNestedCopies(t *testing.T) {
+	src := &Account{
+		Owner: &shared.Owner{Roles: []string{"admin"}, Manager: &shared.Owner{Name: "root"}},
+		Plan:  shared.Plan{Features: []string{"sso"}},
+	}
+	got := ConvertModelAccountToDtoAccount(src)
+	src.Owner.Roles[0] = "guest"
+	src.Owner.Manager.Name = "guest"
+	src.Plan.Features[0] = "none"
+	if got.Owner.Roles[0] != "admin" || got.Owner.Manager.Name != "root" || got.Plan.Features[0] != "sso" {
+		t.Fatalf("copy shares nested values: %+v %+v %+v", got.Owner, got.Owner.Manager, got.Plan)
+	}
+}
+`)
+}
+
+func TestRunner_Run_RangeChecksNarrowingCasts(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "narrowing_gen.go")
+
+	cfg := &Config{
+		SrcType:   "Reading",
+		SrcPath:   "github.com/seitarof/gen-dto/testdata/narrowing/model",
+		DstType:   "Reading",
+		DstPath:   "github.com/seitarof/gen-dto/testdata/narrowing/dto",
+		Filename:  out,
+		Narrowing: resolver.NarrowingCheck,
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"func ConvertModelReadingToDtoReading(src *Reading) (*dto.Reading, error)",
+		"if src.Count < math.MinInt32 || src.Count > math.MaxInt32 {",
+		`return nil, fmt.Errorf("gen-dto: Count: value %v overflows int32", src.Count)`,
+		"if src.Level < 0 || src.Level > math.MaxUint8 {",
+		"if *src.Limit < math.MinInt16 || *src.Limit > math.MaxInt16 {",
+		"if v, err := ConvertModelGaugeToDtoGauge(&src.Gauge); err != nil {",
+		"return dst, nil",
+		"dst.Count = (int64)(src.Count)",
+		"if math.IsNaN(src.Total) || src.Total < math.MinInt64 || src.Total >= 0x1p63 {",
+		"if int64(float64(src.Sum)) != src.Sum {",
+		`return nil, fmt.Errorf("gen-dto: Sum: value %v loses precision in float64", src.Sum)`,
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+}
+
+func TestRunner_Run_RoundTripsIntegersConvertedToFloats(t *testing.T) {
+	tests := []struct {
+		name  string
+		clamp bool
+		test  string
+	}{
+		{
+			name: "check",
+			test: `package model
+
+import "testing"
+
+func TestSum(t *testing.T) {
+	got, err := ConvertModelReadingToDtoReading(&Reading{Sum: 1 << 60})
+	if err != nil || got.Sum != 1<<60 {
+		t.Fatalf("Sum 1<<60 = %v, %v", got, err)
+	}
+	if _, err := ConvertModelReadingToDtoReading(&Reading{Sum: 1<<60 + 1}); err == nil {
+		t.Fatal("Sum 1<<60+1 should not convert exactly")
+	}
+}
+`,
+		},
+		{
+			name:  "clamp",
+			clamp: true,
+			test: `package model
+
+import "testing"
+
+func TestSum(t *testing.T) {
+	if got := ConvertModelReadingToDtoReading(&Reading{Sum: 1 << 60}); got.Sum != 1<<60 {
+		t.Fatalf("Sum 1<<60 = %v", got.Sum)
+	}
+	if got := ConvertModelReadingToDtoReading(&Reading{Sum: 1<<60 + 1}); got.Sum != 1<<60 {
+		t.Fatalf("Sum 1<<60+1 = %v, want it rounded", got.Sum)
+	}
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "narrowing_gen.go")
+			cfg := &Config{
+				SrcType:        "Reading",
+				SrcPath:        "github.com/seitarof/gen-dto/testdata/narrowing/model",
+				DstType:        "Reading",
+				DstPath:        "github.com/seitarof/gen-dto/testdata/narrowing/dto",
+				Filename:       out,
+				Narrowing:      resolver.NarrowingCheck,
+				NarrowingClamp: tt.clamp,
+			}
+
+			runner := NewRunner(
+				parser.New(),
+				matcher.NewStructMatcher(),
+				matcher.NewFieldMatcher(),
+				resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+				generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+			)
+			if err := runner.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			testGenerated(t, cfg.SrcPath, out, tt.test)
+		})
+	}
+}
+
+func TestRunner_Run_ComposesNestedContainerConversions(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "containers_gen.go")
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	cfg := &Config{
+		SrcType:  "Report",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/containers/model",
+		DstType:  "Report",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/containers/dto",
+		Filename: out,
+	}
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"dst.Matrix[idx0][i] = int64(src.Matrix[idx0][i])",
+		"dst.Times[idx0] = src.Times[idx0].Format(time.RFC3339)",
+		"dst.Counts[i] = int64((*src.Counts)[i])",
+		"dval0 = val0.Format(time.RFC3339)",
+		"ConvertModelCellToDtoCell(&src.Grid[idx0][i])",
+		"dkey0 = (int64)(key0)",
+		"dst.ByID[dkey0] = dval0",
+		"if parsed, err := time.Parse(time.RFC3339, src.Times[idx0]); err == nil {",
+		"dst.Counts = &pval0",
+		"dst.MaybeTime = src.MaybeTime.Format(time.RFC3339)",
+		"dst.MaybeTime = &parsed",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	if strings.Contains(got, "TODO") {
+		t.Fatalf("all container fields should be converted\n%s", got)
+	}
+	if strings.Contains(got, "new(") {
+		t.Fatalf("pointer destinations should stay nil until converted\n%s", got)
+	}
+}
+
+func TestRunner_Run_HandlesPointerElementSlices(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "ptrslice_gen.go")
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	cfg := &Config{
+		SrcType:  "Cart",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/ptrslice/model",
+		DstType:  "Cart",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/ptrslice/dto",
+		Filename: out,
+	}
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"if v := ConvertModelItemToDtoItem(&src.Values[i]); v != nil {",
+		"dst.Pointers[i] = ConvertModelItemToDtoItem(src.Pointers[i])",
+		"if v := ConvertModelItemToDtoItem(src.ToValues[i]); v != nil {",
+		"dst.ToPointer[i] = ConvertModelItemToDtoItem(&src.ToPointer[i])",
+		"if src.Notes[idx0] != nil {",
+		"dst.Notes[idx0] = *src.Notes[idx0]",
+		"v := src.Codes[idx0]",
+		"dst.Codes[idx0] = &v",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	if strings.Contains(got, "(&src.Pointers[i])") || strings.Contains(got, "dst.Pointers[i] = *v") || strings.Contains(got, "= &src.Codes[idx0]") {
+		t.Fatalf("pointer elements must not be re-addressed or dereferenced\n%s", got)
+	}
+}
+
+func TestRunner_Run_FlattensAndUnflattensNestedFields(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "flatten_gen.go")
+
+	cfg := &Config{
+		SrcType:       "Customer",
+		SrcPath:       "github.com/seitarof/gen-dto/testdata/flatten/model",
+		DstType:       "Customer",
+		DstPath:       "github.com/seitarof/gen-dto/testdata/flatten/dto",
+		Filename:      out,
+		MatchPaths:    true,
+		FieldMappings: []matcher.PathMapping{{From: "Address.Zip", To: "PostalCode"}},
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcherWithOptions(cfg.MatcherOptions()),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"if src.Address != nil {\n\t\tdst.AddressCity = src.Address.City",
+		"if src.Address != nil && src.Address.Geo != nil {\n\t\tdst.AddressGeoLat = src.Address.Geo.Lat",
+		"dst.BillingCity = src.Billing.City",
+		"dst.PostalCode = src.Address.Zip",
+		"if dst.Address == nil {\n\t\tdst.Address = new(Address)\n\t}\n\tdst.Address.City = src.AddressCity",
+		"if dst.Address.Geo == nil {\n\t\tdst.Address.Geo = new(Geo)\n\t}\n\tdst.Address.Geo.Lat = src.AddressGeoLat",
+		"dst.Billing.City = src.BillingCity",
+		"dst.Address.Zip = src.PostalCode",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+}
+
+func TestRunner_Run_GuardsEmbeddedPointerStructs(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "embedptr_gen.go")
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	cfg := &Config{
+		SrcType:  "Record",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/embedptr/model",
+		DstType:  "Record",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/embedptr/dto",
+		Filename: out,
+	}
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"if src.Audit != nil {\n\t\tdst.CreatedBy = src.Audit.CreatedBy\n\t}",
+		"if dst.Audit == nil {\n\t\tdst.Audit = new(Audit)\n\t}\n\tdst.Audit.CreatedBy = src.CreatedBy",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+}
+
+func TestRunner_Run_ConvertsEmbeddedStructsAsUnits(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "embedunit_gen.go")
+
+	cfg := &Config{
+		SrcType:       "User",
+		SrcPath:       "github.com/seitarof/gen-dto/testdata/embedunit/model",
+		DstType:       "User",
+		DstPath:       "github.com/seitarof/gen-dto/testdata/embedunit/dto",
+		Filename:      out,
+		EmbeddedUnits: true,
+	}
+
+	runner := NewRunner(
+		parser.NewWithOptions(cfg.ParserOptions()),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcherWithOptions(cfg.MatcherOptions()),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"dst.Audit = ConvertModelAuditToDtoAudit(src.Audit)",
+		"if v := ConvertModelTimestampsToDtoTimestamps(&src.Timestamps); v != nil {\n\t\tdst.Timestamps = *v",
+		"if v := ConvertDtoTimestampsToModelTimestamps(&src.Timestamps); v != nil {\n\t\tdst.Timestamps = *v",
+		"dst.Audit = ConvertDtoAuditToModelAudit(src.Audit)",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	if strings.Contains(got, "CreatedAt = src.Timestamps.CreatedAt") || strings.Contains(got, "src.Audit.CreatedBy") {
+		t.Fatalf("embedded fields should not be converted one by one\n%s", got)
+	}
+}
+
+func TestRunner_Run_SupportsGenericStructs(t *testing.T) {
+	tests := []struct {
+		name    string
+		srcType string
+		checks  []string
+	}{
+		{
+			name:    "instantiated root",
+			srcType: "Page[User]",
+			checks: []string{
+				"func ConvertModelPageUserToDtoPageUser(src *Page[User]) *dto.Page[dto.User] {",
+				"dst := &dto.Page[dto.User]{}",
+				"func ConvertDtoPageUserToModelPageUser(src *dto.Page[dto.User]) *Page[User] {",
+			},
+		},
+		{
+			name:    "nested instantiations",
+			srcType: "Feed",
+			checks: []string{
+				"if v := ConvertModelPageUserToDtoPageUser(&src.Users); v != nil {",
+				"dst.Latest = ConvertModelBoxUserToDtoBoxUser(src.Latest)",
+				"func ConvertModelBoxIntToDtoBoxInt(src *Box[int]) *dto.Box[int] {",
+				"func ConvertModelBoxUserToDtoBoxUser(src *Box[User]) *dto.Box[dto.User] {",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "generics_gen.go")
+
+			runner := NewRunner(
+				parser.New(),
+				matcher.NewStructMatcher(),
+				matcher.NewFieldMatcher(),
+				resolver.New(resolver.DefaultRules()...),
+				generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+			)
+
+			cfg := &Config{
+				SrcType:  tt.srcType,
+				SrcPath:  "github.com/seitarof/gen-dto/testdata/generics/model",
+				DstType:  tt.srcType,
+				DstPath:  "github.com/seitarof/gen-dto/testdata/generics/dto",
+				Filename: out,
+			}
+
+			if err := runner.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			typeCheckGenerated(t, cfg.SrcPath, out)
+			got := string(content)
+
+			for _, check := range tt.checks {
+				if !strings.Contains(got, check) {
+					t.Fatalf("generated code does not contain %q\n%s", check, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Run_ConvertsUserDefinedWrappers(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "wrappers_gen.go")
+
+	cfg := &Config{
+		SrcType:  "Profile",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/wrappers/model",
+		DstType:  "Profile",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/wrappers/dto",
+		Filename: out,
+		Wrappers: []resolver.Wrapper{
+			{Type: "github.com/seitarof/gen-dto/testdata/wrappers/optional.Value", Value: "V", Valid: "Set"},
+			{Type: "github.com/seitarof/gen-dto/testdata/wrappers/optional.Maybe", Value: "Get()", Valid: "IsPresent()", Constructor: "Of"},
+		},
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"if src.Nickname.Set {\n\t\tv := src.Nickname.V\n\t\tdst.Nickname = &v\n\t}",
+		"if src.Age.Set {\n\t\tdst.Age = src.Age.V\n\t}",
+		"if src.Bio.IsPresent() {\n\t\tdst.Bio = optional.Value[string]{V: src.Bio.Get(), Set: true}\n\t}",
+		"if src.Score.IsPresent() {\n\t\tv := src.Score.Get()\n\t\tdst.Score = &v\n\t}",
+		"if src.Nickname != nil {\n\t\tdst.Nickname = optional.Value[string]{V: *src.Nickname, Set: true}\n\t}",
+		"dst.Age = optional.Value[int]{V: src.Age, Set: true}",
+		"if src.Bio.Set {\n\t\tdst.Bio = optional.Of(src.Bio.V)\n\t}",
+		"if src.Score != nil {\n\t\tdst.Score = optional.Of(*src.Score)\n\t}",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+}
+
+func TestRunner_Run_ConvertsAccessibleUnexportedFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		dstType    string
+		dstPath    string
+		unexported bool
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:       "other package",
+			dstType:    "Account",
+			dstPath:    "github.com/seitarof/gen-dto/testdata/unexported/dto",
+			unexported: true,
+			want:       []string{"dst.Balance = src.balance", "dst.balance = src.Balance"},
+			notWant:    []string{"note"},
+		},
+		{
+			name:       "same package",
+			dstType:    "AccountRecord",
+			dstPath:    "github.com/seitarof/gen-dto/testdata/unexported/model",
+			unexported: true,
+			want:       []string{"dst.balance = src.balance", "dst.note = src.note"},
+		},
+		{
+			name:       "disabled",
+			dstType:    "Account",
+			dstPath:    "github.com/seitarof/gen-dto/testdata/unexported/dto",
+			unexported: false,
+			want:       []string{"dst.ID = src.ID"},
+			notWant:    []string{"balance", "note"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "unexported_gen.go")
+
+			cfg := &Config{
+				SrcType:    "Account",
+				SrcPath:    "github.com/seitarof/gen-dto/testdata/unexported/model",
+				DstType:    tt.dstType,
+				DstPath:    tt.dstPath,
+				Filename:   out,
+				Unexported: tt.unexported,
+			}
+
+			runner := NewRunner(
+				parser.NewWithOptions(cfg.ParserOptions()),
+				matcher.NewStructMatcher(),
+				matcher.NewFieldMatcher(),
+				resolver.New(resolver.DefaultRules()...),
+				generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+			)
+
+			if err := runner.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			typeCheckGenerated(t, cfg.SrcPath, out)
+			got := string(content)
+
+			for _, check := range tt.want {
+				if !strings.Contains(got, check) {
+					t.Fatalf("generated code does not contain %q\n%s", check, got)
+				}
+			}
+			for _, check := range tt.notWant {
+				if strings.Contains(got, check) {
+					t.Fatalf("generated code should not contain %q\n%s", check, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Run_UsesGettersAndSetters(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "accessors_gen.go")
+
+	cfg := &Config{
+		SrcType:   "Order",
+		SrcPath:   "github.com/seitarof/gen-dto/testdata/accessors/model",
+		DstType:   "Order",
+		DstPath:   "github.com/seitarof/gen-dto/testdata/accessors/dto",
+		Filename:  out,
+		Accessors: true,
+	}
+
+	runner := NewRunner(
+		parser.NewWithOptions(cfg.ParserOptions()),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcherWithOptions(cfg.MatcherOptions()),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"dst.ID = src.GetID()",
+		"gv := src.GetTotal()\n\t\tif v := ConvertModelMoneyToDtoMoney(&gv); v != nil {",
+		"gv := src.GetNote()\n\t\tif gv != nil {\n\t\t\tdst.Note = *gv",
+		"dst.SetID(src.ID)",
+		"dst.SetNote(&src.Note)",
+		"var sv Money\n\t\tif v := ConvertDtoMoneyToModelMoney(&src.Total); v != nil {\n\t\t\tsv = *v\n\t\t}\n\t\tdst.SetTotal(sv)",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	if n := strings.Count(got, "src.GetNote()"); n != 1 {
+		t.Fatalf("GetNote() is called %d times, want 1\n%s", n, got)
+	}
+	// Setters follow their declaration order, not the alphabetical one.
+	setters := []string{"dst.SetID(", "dst.SetTotal(", "dst.SetTags(", "dst.SetNote("}
+	for i := 1; i < len(setters); i++ {
+		if strings.Index(got, setters[i-1]) > strings.Index(got, setters[i]) {
+			t.Fatalf("%s should be called before %s\n%s", setters[i-1], setters[i], got)
+		}
+	}
+}
+
+func TestRunner_Run_ConvertsProtobufMessages(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "protobuf_gen.go")
+
+	cfg := &Config{
+		SrcType:  "Event",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/protobuf/domain",
+		DstType:  "Event",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/protobuf/eventpb",
+		Filename: out,
+		Protobuf: true,
+	}
+
+	runner := NewRunner(
+		parser.NewWithOptions(cfg.ParserOptions()),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcherWithOptions(cfg.MatcherOptions()),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"dst.CreatedAt = timestamppb.New(src.CreatedAt)",
+		"dst.Ttl = durationpb.New(src.Ttl)",
+		"if src.Title != nil {\n\t\tdst.Title = wrapperspb.String(*src.Title)\n\t}",
+		"dst.Priority = wrapperspb.Int64(src.Priority)",
+		"if src.Text != nil {\n\t\tdst.Payload = &eventpb.Event_Text{Text: *src.Text}\n\t}",
+		"dst.Payload = &eventpb.Event_Attachment{Attachment: ConvertDomainAttachmentToEventpbAttachment(src.Attachment)}",
+		"if src.CreatedAt != nil {\n\t\tdst.CreatedAt = src.CreatedAt.AsTime()\n\t}",
+		"dst.Ttl = src.Ttl.AsDuration()",
+		"v := src.Title.GetValue()\n\t\tdst.Title = &v",
+		"if ov, ok := src.Payload.(*eventpb.Event_Text); ok {\n\t\tdst.Text = &ov.Text\n\t}",
+		"if ov, ok := src.Payload.(*eventpb.Event_Attachment); ok {\n\t\tdst.Attachment = ConvertEventpbAttachmentToDomainAttachment(ov.Attachment)\n\t}",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	if strings.Contains(got, "TODO") {
+		t.Fatalf("generated code should convert every field\n%s", got)
+	}
+}
+
+func TestRunner_Run_ConvertsPolymorphicFields(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "polymorphic_gen.go")
+
+	cfg := &Config{
+		SrcType:  "Order",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/polymorphic/model",
+		DstType:  "Order",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/polymorphic/dto",
+		Filename: out,
+		Polymorphic: []resolver.Polymorphic{{
+			Field: "Payment",
+			Variants: []resolver.Variant{
+				{Src: "Card", Dst: "CardDTO"},
+				{Src: "BankTransfer", Dst: "BankTransferDTO"},
+			},
+		}},
+		VariantFallback: resolver.VariantFallbackPanic,
+	}
+
+	runner := NewRunner(
+		parser.NewWithOptions(cfg.ParserOptions()),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcherWithOptions(cfg.MatcherOptions()),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+
+	checks := []string{
+		"switch v := src.Payment.(type) {",
+		"case *Card:\n\t\tif v != nil {\n\t\t\tc := ConvertCardToCardDTO(v)\n\t\t\tdst.Payment = c\n\t\t}",
+		"case BankTransfer:\n\t\tc := ConvertBankTransferToBankTransferDTO(&v)\n\t\tif c != nil {\n\t\t\tdst.Payment = *c\n\t\t}",
+		"case *BankTransfer:\n\t\tif v != nil {\n\t\t\tc := ConvertBankTransferToBankTransferDTO(v)",
+		"case *dto.CardDTO:\n\t\tif v != nil {\n\t\t\tc := ConvertCardDTOToCard(v)",
+		"case dto.BankTransferDTO:",
+		"case *dto.BankTransferDTO:\n\t\tif v != nil {",
+		"case nil:\n\tdefault:\n\t\tpanic(fmt.Sprintf(\"unsupported Payment variant %T\", v))",
+		"func ConvertCardToCardDTO(src *Card) *dto.CardDTO {",
+		"func ConvertBankTransferDTOToBankTransfer(src *dto.BankTransferDTO) *BankTransfer {",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	if strings.Contains(got, "Voucher") {
+		t.Fatalf("undeclared variants should be left to the fallback\n%s", got)
+	}
+}
+
+func TestRunner_Run_AssignsDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		override bool
+		checks   []string
+		absent   []string
+	}{
+		{
+			name: "defaults win",
+			checks: []string{
+				"dst := &dto.User{}\n\tdst.Kind = \"User\"\n\tdst.APIVersion = 2\n\tdst.Status = \"active\"\n\tdst.Score = 0.5\n",
+			},
+			absent: []string{"dst.Status = (dto.Status)(src.Status)", "if src.Score != 0"},
+		},
+		{
+			name:     "source overrides",
+			override: true,
+			checks: []string{
+				"dst.Status = \"active\"\n\tdst.Score = 0.5\n",
+				"if src.Status != \"\" {\n\t\tdst.Status = (dto.Status)(src.Status)\n\t}",
+				"if src.Score != 0 {\n\t\tdst.Score = src.Score\n\t}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "defaults_gen.go")
+			cfg := &Config{
+				SrcType:  "User",
+				SrcPath:  "github.com/seitarof/gen-dto/testdata/defaults/model",
+				DstType:  "User",
+				DstPath:  "github.com/seitarof/gen-dto/testdata/defaults/dto",
+				Filename: out,
+				Defaults: []FieldDefault{
+					{Field: "Kind", Expr: `"User"`},
+					{Field: "APIVersion", Expr: "2"},
+				},
+				SourceOverridesDefault: tt.override,
+			}
+
+			runner := NewRunner(
+				parser.New(),
+				matcher.NewStructMatcher(),
+				matcher.NewFieldMatcher(),
+				resolver.New(resolver.DefaultRules()...),
+				generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+			)
+			if err := runner.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			typeCheckGenerated(t, cfg.SrcPath, out)
+			got := string(content)
+			for _, check := range tt.checks {
+				if !strings.Contains(got, check) {
+					t.Fatalf("generated code does not contain %q\n%s", check, got)
+				}
+			}
+			for _, check := range tt.absent {
+				if strings.Contains(got, check) {
+					t.Fatalf("generated code should not contain %q\n%s", check, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Run_RejectsMistypedDefaults(t *testing.T) {
+	cfg := &Config{
+		SrcType:  "User",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/defaults/model",
+		DstType:  "User",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/defaults/dto",
+		Filename: filepath.Join(t.TempDir(), "defaults_gen.go"),
+		Defaults: []FieldDefault{{Field: "APIVersion", Expr: `"two"`}},
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.DefaultRules()...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+	err := runner.Run(cfg)
+	if err == nil {
+		t.Fatal("expected error for mistyped default, got nil")
+	}
+	if !strings.Contains(err.Error(), `--default APIVersion="two"`) || !strings.Contains(err.Error(), "cannot use") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunner_Run_AssignsComputedFields(t *testing.T) {
+	dir := t.TempDir()
+	computedPath := filepath.Join(dir, "computed.yaml")
+	config := "# derived fields\n" +
+		"FullName: 'src.FirstName + \" \" + src.LastName'\n" +
+		"\n" +
+		"Age: \"int(time.Since(src.BirthDate).Hours() / 24 / 365)\"\n"
+	if err := os.WriteFile(computedPath, []byte(config), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	newConfig := func() *Config {
+		cfg, err := ParseArgs([]string{
+			"--src-type", "User",
+			"--src-path", "github.com/seitarof/gen-dto/testdata/computed/model",
+			"--dst-type", "User",
+			"--dst-path", "github.com/seitarof/gen-dto/testdata/computed/dto",
+			"--filename", filepath.Join(dir, "computed_gen.go"),
+			"--computed", computedPath,
+		})
+		if err != nil {
+			t.Fatalf("ParseArgs() error = %v", err)
+		}
+		return cfg
+	}
+	newRunner := func(cfg *Config) Runner {
+		return NewRunner(
+			parser.NewWithOptions(cfg.ParserOptions()),
+			matcher.NewStructMatcher(),
+			matcher.NewFieldMatcherWithOptions(cfg.MatcherOptions()),
+			resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+			generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+		)
+	}
+
+	cfg := newConfig()
+	if err := newRunner(cfg).Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	content, err := os.ReadFile(cfg.Filename)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, cfg.Filename)
+	got := string(content)
+	checks := []string{
+		`dst.FullName = src.FirstName + " " + src.LastName`,
+		"dst.Age = int(time.Since(src.BirthDate).Hours() / 24 / 365)",
+		`"time"`,
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+
+	if err := os.WriteFile(computedPath, []byte(config+"ID: src.BirthDate\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg = newConfig()
+	err = newRunner(cfg).Run(cfg)
+	if err == nil {
+		t.Fatal("expected error for mistyped computed field, got nil")
+	}
+	if want := computedPath + ":5: ID: cannot use src.BirthDate"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("error %q does not contain %q", err, want)
+	}
+}
+
+func TestRunner_Run_CallsConversionHooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		narrowing resolver.NarrowingPolicy
+		checks    []string
+		absent    []string
+	}{
+		{
+			name: "plain converters",
+			checks: []string{
+				"\tdst.Email = src.Email\n\tafterConvertUserToUserResponse(src, dst)\n\treturn dst\n",
+			},
+			absent: []string{"beforeConvertUserResponseToUser"},
+		},
+		{
+			name:      "error-returning converters",
+			narrowing: resolver.NarrowingCheck,
+			checks: []string{
+				"dst := &User{}\n\tif err := beforeConvertUserResponseToUser(src, dst); err != nil {\n\t\treturn nil, err\n\t}\n\tdst.ID = src.ID",
+				"afterConvertUserToUserResponse(src, dst)\n\treturn dst, nil\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "hooks_gen.go")
+			cfg := &Config{
+				SrcType:   "User",
+				SrcPath:   "github.com/seitarof/gen-dto/testdata/hooks/model",
+				DstType:   "UserResponse",
+				DstPath:   "github.com/seitarof/gen-dto/testdata/hooks/dto",
+				Filename:  out,
+				Narrowing: tt.narrowing,
+			}
+
+			runner := NewRunner(
+				parser.New(),
+				matcher.NewStructMatcher(),
+				matcher.NewFieldMatcher(),
+				resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+				generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+			)
+			if err := runner.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			typeCheckGenerated(t, cfg.SrcPath, out)
+			got := string(content)
+			for _, check := range tt.checks {
+				if !strings.Contains(got, check) {
+					t.Fatalf("generated code does not contain %q\n%s", check, got)
+				}
+			}
+			for _, check := range tt.absent {
+				if strings.Contains(got, check) {
+					t.Fatalf("generated code should not contain %q\n%s", check, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Run_GeneratesFuncShapes(t *testing.T) {
+	tests := []struct {
+		name      string
+		shape     resolver.FuncShape
+		narrowing resolver.NarrowingPolicy
+		checks    []string
+	}{
+		{
+			name:  "value",
+			shape: resolver.FuncShapeValue,
+			checks: []string{
+				"func ConvertUserToUserResponse(src User) dto.UserResponse {\n\tdst := dto.UserResponse{}",
+				"dst.Home = ConvertModelAddressToDtoAddress(src.Home)",
+				"if src.Work != nil {\n\t\tdst.Work = new(dto.Address)\n\t\t*dst.Work = ConvertModelAddressToDtoAddress(*src.Work)\n\t}",
+				"\treturn dst\n}",
+			},
+		},
+		{
+			name:      "value returning errors",
+			shape:     resolver.FuncShapeValue,
+			narrowing: resolver.NarrowingCheck,
+			checks: []string{
+				"func ConvertUserToUserResponse(src User) (dto.UserResponse, error) {",
+				"if v, err := ConvertModelAddressToDtoAddress(src.Home); err != nil {\n\t\treturn dto.UserResponse{}, err\n\t} else {\n\t\tdst.Home = v\n\t}",
+			},
+		},
+		{
+			name:  "method",
+			shape: resolver.FuncShapeMethod,
+			checks: []string{
+				"func (src *User) ToResponse() *dto.UserResponse {",
+				"func (src *Address) ToAddress() *dto.Address {",
+				"if v := src.Home.ToAddress(); v != nil {",
+				"dst.Work = src.Work.ToAddress()",
+				// dto types are not declared in the output package.
+				"func ConvertUserResponseToUser(src *dto.UserResponse) *User {",
+				"dst.Work = ConvertDtoAddressToModelAddress(src.Work)",
+			},
+		},
+		{
+			name:  "into",
+			shape: resolver.FuncShapeInto,
+			checks: []string{
+				"func ConvertUserToUserResponse(dst *dto.UserResponse, src *User) {\n\tif src == nil || dst == nil {\n\t\treturn\n\t}",
+				"ConvertModelAddressToDtoAddress(&dst.Home, &src.Home)",
+				"if src.Work != nil {\n\t\tif dst.Work == nil {\n\t\t\tdst.Work = new(dto.Address)\n\t\t}\n\t\tConvertModelAddressToDtoAddress(dst.Work, src.Work)\n\t} else {\n\t\tdst.Work = nil\n\t}",
+			},
+		},
+		{
+			name:      "into returning errors",
+			shape:     resolver.FuncShapeInto,
+			narrowing: resolver.NarrowingCheck,
+			checks: []string{
+				"func ConvertUserToUserResponse(dst *dto.UserResponse, src *User) error {",
+				"if err := ConvertModelAddressToDtoAddress(&dst.Home, &src.Home); err != nil {\n\t\treturn err\n\t}",
+				"\treturn nil\n}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "shapes_gen.go")
+			cfg := &Config{
+				SrcType:   "User",
+				SrcPath:   "github.com/seitarof/gen-dto/testdata/shapes/model",
+				DstType:   "UserResponse",
+				DstPath:   "github.com/seitarof/gen-dto/testdata/shapes/dto",
+				Filename:  out,
+				Narrowing: tt.narrowing,
+				FuncShape: tt.shape,
+			}
+
+			runner := NewRunner(
+				parser.New(),
+				matcher.NewStructMatcher(),
+				matcher.NewFieldMatcher(),
+				resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+				generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+			)
+			if err := runner.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			typeCheckGenerated(t, cfg.SrcPath, out)
+			got := string(content)
+			for _, check := range tt.checks {
+				if !strings.Contains(got, check) {
+					t.Fatalf("generated code does not contain %q\n%s", check, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Run_GeneratesCollectionConverters(t *testing.T) {
+	tests := []struct {
+		name        string
+		srcType     string
+		dstType     string
+		collections []resolver.CollectionKind
+		shape       resolver.FuncShape
+		checks      []string
+		absent      []string
+	}{
+		{
+			name:        "collections of every pair",
+			srcType:     "User",
+			dstType:     "UserResponse",
+			collections: []resolver.CollectionKind{resolver.CollectionSlice, resolver.CollectionPointerSlice, resolver.CollectionMap},
+			checks: []string{
+				"func ConvertUsersToUserResponses(src []User) []dto.UserResponse {",
+				"\tfor i := range src {\n\t\tif v := ConvertUserToUserResponse(&src[i]); v != nil {\n\t\t\tdst[i] = *v\n\t\t}\n\t}",
+				"func ConvertUserPtrsToUserResponsePtrs(src []*User) []*dto.UserResponse {",
+				"dst[i] = ConvertUserToUserResponse(src[i])",
+				"func ConvertUserMapToUserResponseMap[K comparable](src map[K]User) map[K]dto.UserResponse {",
+				"func ConvertModelAddressesToDtoAddresses(src []Address) []dto.Address {",
+				"func ConvertUserResponsesToUsers(src []dto.UserResponse) []User {",
+			},
+		},
+		{
+			name:    "named slice roots",
+			srcType: "Users",
+			dstType: "UserResponses",
+			checks: []string{
+				"func ConvertUserToUserResponse(src *User) *dto.UserResponse {",
+				"func ConvertUsersToUserResponses(src Users) dto.UserResponses {\n\tif src == nil {\n\t\treturn nil\n\t}\n\tdst := make(dto.UserResponses, len(src))",
+				"func ConvertUserResponsesToUsers(src dto.UserResponses) Users {",
+			},
+			absent: []string{"func ConvertModelAddressesToDtoAddresses"},
+		},
+		{
+			name:        "named map roots with value converters",
+			srcType:     "UsersByID",
+			dstType:     "UserResponsesByID",
+			collections: []resolver.CollectionKind{resolver.CollectionSlice},
+			shape:       resolver.FuncShapeValue,
+			checks: []string{
+				"func ConvertUsersByIDToUserResponsesByID(src UsersByID) dto.UserResponsesByID {",
+				"\tfor k, s := range src {\n\t\tvar d dto.UserResponse\n\t\tif s != nil {\n\t\t\td = ConvertUserToUserResponse(*s)\n\t\t}\n\t\tdst[k] = d\n\t}",
+				"func ConvertUsersToUserResponses(src []User) []dto.UserResponse {",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "collections_gen.go")
+			cfg := &Config{
+				SrcType:     tt.srcType,
+				SrcPath:     "github.com/seitarof/gen-dto/testdata/collections/model",
+				DstType:     tt.dstType,
+				DstPath:     "github.com/seitarof/gen-dto/testdata/collections/dto",
+				Filename:    out,
+				Collections: tt.collections,
+				FuncShape:   tt.shape,
+			}
+
+			runner := NewRunner(
+				parser.New(),
+				matcher.NewStructMatcher(),
+				matcher.NewFieldMatcher(),
+				resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+				generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+			)
+			if err := runner.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			typeCheckGenerated(t, cfg.SrcPath, out)
+			got := string(content)
+			for _, check := range tt.checks {
+				if !strings.Contains(got, check) {
+					t.Fatalf("generated code does not contain %q\n%s", check, got)
+				}
+			}
+			for _, check := range tt.absent {
+				if strings.Contains(got, check) {
+					t.Fatalf("generated code should not contain %q\n%s", check, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Run_RejectsMismatchedCollectionRoots(t *testing.T) {
+	cfg := &Config{
+		SrcType:  "Users",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/collections/model",
+		DstType:  "UserResponsesByID",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/collections/dto",
+		Filename: filepath.Join(t.TempDir(), "collections_gen.go"),
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+	err := runner.Run(cfg)
+	if err == nil || !strings.Contains(err.Error(), "must both be structs, slices or maps") {
+		t.Fatalf("Run() error = %v, want mismatched collections", err)
+	}
+}
+
+func TestRunner_Run_GeneratesPatchFunctions(t *testing.T) {
+	tests := []struct {
+		name    string
+		nonZero bool
+		checks  []string
+		absent  []string
+	}{
+		{
+			name: "non-nil fields",
+			checks: []string{
+				"func ApplyUserPatch(dst *model.User, src *UserPatchRequest) {\n\tif src == nil || dst == nil {\n\t\treturn\n\t}",
+				"\tif src.Name != nil {\n\t\tdst.Name = *src.Name\n\t}",
+				"\tif src.Age != nil {\n\t\tdst.Age = (int32)(*src.Age)\n\t}",
+				"\tif src.Tags != nil {\n\t\tdst.Tags = src.Tags\n\t}",
+				"\tdst.Birthday = src.Birthday\n",
+				"\tif src.Address != nil {\n\t\tApplyAddressPatch(&dst.Address, src.Address)\n\t}",
+				"\tif src.Billing != nil {\n\t\tif dst.Billing == nil {\n\t\t\tdst.Billing = new(model.Address)\n\t\t}\n\t\tApplyAddressPatch(dst.Billing, src.Billing)\n\t}",
+				"func ApplyAddressPatch(dst *model.Address, src *AddressPatch) {",
+				"\tdst.Country = src.Country\n",
+			},
+			// Patches are not converted back.
+			absent: []string{"ConvertUserToUserPatchRequest", "else"},
+		},
+		{
+			name:    "non-zero fields",
+			nonZero: true,
+			checks: []string{
+				"\tif !src.Birthday.IsZero() {\n\t\tdst.Birthday = src.Birthday\n\t}",
+				"\tif src.Country != \"\" {\n\t\tdst.Country = src.Country\n\t}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "patch_gen.go")
+			cfg := &Config{
+				SrcType:      "UserPatchRequest",
+				SrcPath:      "github.com/seitarof/gen-dto/testdata/patch/dto",
+				DstType:      "User",
+				DstPath:      "github.com/seitarof/gen-dto/testdata/patch/model",
+				Filename:     out,
+				Patch:        true,
+				PatchNonZero: tt.nonZero,
+			}
+
+			runner := NewRunner(
+				parser.New(),
+				matcher.NewStructMatcher(),
+				matcher.NewFieldMatcher(),
+				resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+				generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+			)
+			if err := runner.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			typeCheckGenerated(t, cfg.SrcPath, out)
+			got := string(content)
+			for _, check := range tt.checks {
+				if !strings.Contains(got, check) {
+					t.Fatalf("generated code does not contain %q\n%s", check, got)
+				}
+			}
+			for _, check := range tt.absent {
+				if strings.Contains(got, check) {
+					t.Fatalf("generated code should not contain %q\n%s", check, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Run_MergesSources(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	out := filepath.Join(t.TempDir(), "merge_gen.go")
+	cfg := &Config{
+		SrcType:       "User",
+		MergeSrcTypes: []string{"Profile", "Stats"},
+		SrcPath:       "github.com/seitarof/gen-dto/testdata/merge/model",
+		DstType:       "UserDetailResponse",
+		DstPath:       "github.com/seitarof/gen-dto/testdata/merge/dto",
+		Filename:      out,
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+	checks := []string{
+		"func ConvertUserProfileStatsToUserDetailResponse(user *User, profile *Profile, stats *Stats) *dto.UserDetailResponse {",
+		"\tif user == nil && profile == nil && stats == nil {\n\t\treturn nil\n\t}",
+		"\tif src := user; src != nil {\n\t\tdst.ID = src.ID\n\t\tdst.Name = src.Name\n",
+		"\tif src := profile; src != nil {\n\t\tif user == nil {\n\t\t\tdst.Name = src.Name\n\t\t}\n\t\tdst.Bio = src.Bio\n\t\tif src.AvatarURL != nil {\n\t\t\tdst.AvatarURL = *src.AvatarURL\n\t\t}\n\t}",
+		"\t\tdst.Followers = (int64)(src.Followers)\n",
+		"func ConvertModelAddressToDtoAddress(src *Address) *dto.Address {",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+	// The merged sources are not converted one by one, nor back, and neither
+	// are the structs they nest.
+	for _, absent := range []string{"func ConvertUserToUserDetailResponse", "func ConvertUserDetailResponseTo", "func ConvertDtoAddressToModelAddress"} {
+		if strings.Contains(got, absent) {
+			t.Fatalf("generated code should not contain %q\n%s", absent, got)
+		}
+	}
+
+	warning := `field "Name" of UserDetailResponse matches both User.Name and Profile.Name; using User, listed first in --src-type, unless it is nil`
+	if !strings.Contains(logs.String(), warning) {
+		t.Fatalf("missing ambiguity warning %q in logs:\n%s", warning, logs.String())
+	}
+}
+
+func TestRunner_Run_ComputesMergedFieldsWhateverSourcesAreNil(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "merge_gen.go")
+	cfg := &Config{
+		SrcType:       "User",
+		MergeSrcTypes: []string{"Profile", "Stats"},
+		SrcPath:       "github.com/seitarof/gen-dto/testdata/merge/model",
+		DstType:       "UserDetailResponse",
+		DstPath:       "github.com/seitarof/gen-dto/testdata/merge/dto",
+		Filename:      out,
+		Computed: []ComputedField{
+			{Field: "Activity", Expr: "stats.Describe()", Pos: "computed.txt:1"},
+		},
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "\t}\n\tdst.Activity = stats.Describe()\n\treturn dst\n"; !strings.Contains(string(content), want) {
+		t.Fatalf("generated code does not contain %q\n%s", want, content)
+	}
+	testGenerated(t, cfg.SrcPath, out, `package model
+
+import "testing"
+
+func TestActivity(t *testing.T) {
+	if got := ConvertUserProfileStatsToUserDetailResponse(nil, &Profile{}, nil); got.Activity != "inactive" {
+		t.Fatalf("Activity without user and stats = %q", got.Activity)
+	}
+	if got := ConvertUserProfileStatsToUserDetailResponse(nil, nil, &Stats{Posts: 3}); got.Activity != "3 posts" {
+		t.Fatalf("Activity without user = %q", got.Activity)
+	}
+}
+`)
+
+	// The sources are read by name; there is no single src.
+	cfg.Computed = []ComputedField{{Field: "Activity", Expr: "src.Name", Pos: "computed.txt:1"}}
+	if err := runner.Run(cfg); err == nil || !strings.Contains(err.Error(), "computed.txt:1: Activity: undefined: src") {
+		t.Fatalf("Run() error = %v, want undefined src", err)
+	}
+}
+
+func TestRunner_Run_ThreadsParams(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "params_gen.go")
+	cfg := &Config{
+		SrcType:  "Event",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/params/model",
+		DstType:  "Event",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/params/dto",
+		Filename: out,
+		Params: []resolver.Param{
+			{Name: "loc", Type: "*time.Location"},
+			{Name: "baseURL", Type: "string"},
+		},
+		Computed: []ComputedField{
+			{Field: "Local", Expr: "src.StartsAt.In(loc).Format(time.Kitchen)", Pos: "computed.txt:1"},
+		},
+		Collections: []resolver.CollectionKind{resolver.CollectionSlice},
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+	if err := runner.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	typeCheckGenerated(t, cfg.SrcPath, out)
+	got := string(content)
+	checks := []string{
+		"func ConvertModelEventToDtoEvent(src *Event, loc *time.Location, baseURL string) *dto.Event {",
+		"ConvertModelVenueToDtoVenue(&src.Venue, loc, baseURL)",
+		// The hand-written converter receives the parameters as well.
+		"ConvertModelSessionToDtoSession(&src.Sessions[i], loc, baseURL)",
+		"\tdst.Local = src.StartsAt.In(loc).Format(time.Kitchen)\n",
+		"\tafterConvertModelVenueToDtoVenue(src, dst, loc, baseURL)\n",
+		"func ConvertModelEventsToDtoEvents(src []Event, loc *time.Location, baseURL string) []dto.Event {",
+		"ConvertModelEventToDtoEvent(&src[i], loc, baseURL)",
+		"func ConvertDtoVenueToModelVenue(src *dto.Venue, loc *time.Location, baseURL string) *Venue {",
+	}
+	for _, check := range checks {
+		if !strings.Contains(got, check) {
+			t.Fatalf("generated code does not contain %q\n%s", check, got)
+		}
+	}
+}
+
+func TestRunner_Run_RejectsComputedFieldsReadingUndeclaredParams(t *testing.T) {
+	cfg := &Config{
+		SrcType:  "Event",
+		SrcPath:  "github.com/seitarof/gen-dto/testdata/params/model",
+		DstType:  "Event",
+		DstPath:  "github.com/seitarof/gen-dto/testdata/params/dto",
+		Filename: filepath.Join(t.TempDir(), "params_gen.go"),
+		Computed: []ComputedField{
+			{Field: "Local", Expr: "src.StartsAt.In(loc).Format(time.Kitchen)", Pos: "computed.txt:1"},
+		},
+	}
+
+	runner := NewRunner(
+		parser.New(),
+		matcher.NewStructMatcher(),
+		matcher.NewFieldMatcher(),
+		resolver.New(resolver.RulesWithOptions(cfg.ResolverOptions())...),
+		generator.New(generator.NewGoimportsFormatter(), generator.NewFileWriter()),
+	)
+	err := runner.Run(cfg)
+	if err == nil || !strings.Contains(err.Error(), "computed.txt:1: Local: undefined: loc") {
+		t.Fatalf("Run() error = %v, want undefined loc", err)
+	}
+}
+
+// typeCheckGenerated type-checks the file generated at out as part of the
+// package at pkgPath, which the generator writes into, together with the
+// package's hand-written code.
+func typeCheckGenerated(t *testing.T, pkgPath, out string) {
+	t.Helper()
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedFiles}, pkgPath)
+	if err != nil || len(pkgs) != 1 || len(pkgs[0].GoFiles) == 0 {
+		t.Fatalf("packages.Load(%s) = %v, %v", pkgPath, pkgs, err)
+	}
+
+	dir := filepath.Dir(pkgs[0].GoFiles[0])
+	cfg := &packages.Config{
+		Mode:    packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Overlay: map[string][]byte{filepath.Join(dir, filepath.Base(out)): content},
+	}
+	pkgs, err = packages.Load(cfg, pkgPath)
+	if err != nil {
+		t.Fatalf("packages.Load(%s) error = %v", pkgPath, err)
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			t.Fatalf("generated code does not type-check: %v\n%s", pkg.Errors, content)
+		}
+	}
+}
+
+// testGenerated runs test, a test file of package pkgPath, against the
+// generated file out without writing either into the package directory.
+func testGenerated(t *testing.T, pkgPath, out, test string) {
+	t.Helper()
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedFiles}, pkgPath)
+	if err != nil || len(pkgs) != 1 || len(pkgs[0].GoFiles) == 0 {
+		t.Fatalf("packages.Load(%s) = %v, %v", pkgPath, pkgs, err)
+	}
+	dir := filepath.Dir(pkgs[0].GoFiles[0])
+
+	tmp := t.TempDir()
+	testFile := filepath.Join(tmp, "generated_test.go")
+	if err := os.WriteFile(testFile, []byte(test), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	overlay, err := json.Marshal(map[string]map[string]string{"Replace": {
+		filepath.Join(dir, filepath.Base(out)):  out,
+		filepath.Join(dir, "generated_test.go"): testFile,
+	}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	overlayFile := filepath.Join(tmp, "overlay.json")
+	if err := os.WriteFile(overlayFile, overlay, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cmd := exec.Command("go", "test", "-count=1", "-overlay", overlayFile, pkgPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test %s: %v\n%s", pkgPath, err, output)
+	}
+}
@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
 	"strings"
 	"testing"
 
@@ -120,6 +125,161 @@ func TestRunner_Run_NoMatchingStructs(t *testing.T) {
 	}
 }
 
+func TestRunner_Run_WarnsAboutAmbiguousFields(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	srcUser := &parser.StructInfo{
+		Name:    "User",
+		PkgPath: "example.com/src",
+		PkgName: "model",
+		Ambiguous: []parser.AmbiguousField{
+			{Name: "Code", AccessPaths: []string{"InnerA.Code", "InnerB.Code"}},
+		},
+	}
+	dstUser := &parser.StructInfo{Name: "UserResponse", PkgPath: "example.com/dst", PkgName: "dto"}
+
+	r := NewRunner(
+		&mockParser{srcInfos: []*parser.StructInfo{srcUser}, dstInfos: []*parser.StructInfo{dstUser}},
+		&mockStructMatcher{},
+		&mockFieldMatcher{},
+		&mockResolver{},
+		&mockGenerator{},
+	)
+
+	cfg := &Config{SrcType: "User", SrcPath: "src", DstType: "UserResponse", DstPath: "dst", Filename: "out.go"}
+	if err := r.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := `gen-dto: warning: field "Code" of User is ambiguous between InnerA.Code and InnerB.Code, skipped; pick one with --prefer-path`
+	if !strings.Contains(logs.String(), want) {
+		t.Fatalf("log does not contain %q\n%s", want, logs.String())
+	}
+}
+
+func TestRunner_Run_SkipsExistingConverters(t *testing.T) {
+	srcUser := &parser.StructInfo{Name: "User", PkgPath: "example.com/src", PkgName: "model"}
+	srcAddress := &parser.StructInfo{Name: "Address", PkgPath: "example.com/src", PkgName: "model"}
+	dstUser := &parser.StructInfo{Name: "UserResponse", PkgPath: "example.com/dst", PkgName: "dto"}
+	dstAddress := &parser.StructInfo{Name: "AddressResponse", PkgPath: "example.com/dst", PkgName: "dto"}
+
+	gen := &mockGenerator{}
+	r := NewRunner(
+		&mockParser{
+			srcInfos: []*parser.StructInfo{srcAddress, srcUser},
+			dstInfos: []*parser.StructInfo{dstAddress, dstUser},
+			funcs: []parser.FuncInfo{
+				{Name: "ConvertAddressToAddressResponse", Signature: funcSignature([]types.Type{pointerTo(srcAddress)}, pointerTo(dstAddress))},
+				// A same-named helper with another signature is not a converter.
+				{Name: "ConvertAddressResponseToAddress", Signature: funcSignature([]types.Type{types.Typ[types.String]}, types.Typ[types.String])},
+			},
+		},
+		&mockStructMatcher{pairs: []matcher.StructPair{{Src: srcAddress, Dst: dstAddress}}},
+		&mockFieldMatcher{},
+		&mockResolver{},
+		gen,
+	)
+
+	cfg := &Config{
+		SrcType:       "User",
+		SrcPath:       "src",
+		DstType:       "UserResponse",
+		DstPath:       "dst",
+		Filename:      "out.go",
+		ExistingFuncs: []string{"ConvertUserResponseToUser"},
+	}
+	if err := r.Run(cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := make([]string, 0, len(gen.plans))
+	for _, p := range gen.plans {
+		got = append(got, p.FuncName)
+	}
+	want := []string{"ConvertUserToUserResponse", "ConvertAddressResponseToAddress"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("generated funcs = %v, want %v", got, want)
+	}
+}
+
+func TestRunner_Run_SkipsExistingMergedConverter(t *testing.T) {
+	srcUser := &parser.StructInfo{Name: "User", PkgPath: "example.com/src", PkgName: "model"}
+	srcProfile := &parser.StructInfo{Name: "Profile", PkgPath: "example.com/src", PkgName: "model"}
+	dstUser := &parser.StructInfo{Name: "UserResponse", PkgPath: "example.com/dst", PkgName: "dto"}
+
+	tests := []struct {
+		name string
+		sig  *types.Signature
+		want []string
+	}{
+		{
+			name: "same signature",
+			sig:  funcSignature([]types.Type{pointerTo(srcUser), pointerTo(srcProfile)}, pointerTo(dstUser)),
+		},
+		{
+			// Only the first source does not make the merged converter.
+			name: "other signature",
+			sig:  funcSignature([]types.Type{pointerTo(srcUser)}, pointerTo(dstUser)),
+			want: []string{"ConvertUserProfileToUserResponse"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &mockGenerator{}
+			r := NewRunner(
+				&mockParser{
+					srcInfos: []*parser.StructInfo{srcUser, srcProfile},
+					dstInfos: []*parser.StructInfo{dstUser},
+					funcs:    []parser.FuncInfo{{Name: "ConvertUserProfileToUserResponse", Signature: tt.sig}},
+				},
+				&mockStructMatcher{},
+				&mockFieldMatcher{},
+				&mockResolver{},
+				gen,
+			)
+
+			cfg := &Config{
+				SrcType:       "User",
+				MergeSrcTypes: []string{"Profile"},
+				SrcPath:       "src",
+				DstType:       "UserResponse",
+				DstPath:       "dst",
+				Filename:      "out.go",
+			}
+			if err := r.Run(cfg); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			var got []string
+			for _, p := range gen.plans {
+				got = append(got, p.FuncName)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("generated funcs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func pointerTo(info *parser.StructInfo) types.Type {
+	obj := types.NewTypeName(token.NoPos, types.NewPackage(info.PkgPath, info.PkgName), info.Name, nil)
+	return types.NewPointer(types.NewNamed(obj, types.NewStruct(nil, nil), nil))
+}
+
+func funcSignature(params []types.Type, results ...types.Type) *types.Signature {
+	vars := func(ts []types.Type) *types.Tuple {
+		out := make([]*types.Var, 0, len(ts))
+		for _, t := range ts {
+			out = append(out, types.NewParam(token.NoPos, nil, "", t))
+		}
+		return types.NewTuple(out...)
+	}
+	return types.NewSignatureType(nil, nil, nil, vars(params), vars(results), false)
+}
+
 func TestReverseStructPairs_SkipsSameTypePair(t *testing.T) {
 	user := &parser.StructInfo{Name: "User", PkgPath: "example.com/model"}
 	forward := []matcher.StructPair{
@@ -132,9 +292,58 @@ func TestReverseStructPairs_SkipsSameTypePair(t *testing.T) {
 	}
 }
 
+func TestLogLossyFields(t *testing.T) {
+	plans := []resolver.ConversionPlan{
+		{
+			SrcField: parser.FieldInfo{Name: "Count", TypeStr: "int64"},
+			DstField: parser.FieldInfo{Name: "Count", TypeStr: "int32"},
+			Lossy:    true,
+		},
+		{
+			SrcField: parser.FieldInfo{Name: "Name", TypeStr: "string"},
+			DstField: parser.FieldInfo{Name: "Name", TypeStr: "string"},
+		},
+	}
+	tests := []struct {
+		name      string
+		narrowing resolver.Narrowing
+		want      string
+	}{
+		{name: "allow is silent", narrowing: resolver.Narrowing{Policy: resolver.NarrowingAllow}},
+		{name: "warn", narrowing: resolver.Narrowing{Policy: resolver.NarrowingWarn}, want: "narrowing conversion may lose data"},
+		{name: "check", narrowing: resolver.Narrowing{Policy: resolver.NarrowingCheck}, want: "narrowing conversion range-checked"},
+		{name: "clamp", narrowing: resolver.Narrowing{Policy: resolver.NarrowingCheck, Clamp: true}, want: "narrowing conversion clamped to range or rounded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+
+			logLossyFields(plans, tt.narrowing)
+
+			got := logs.String()
+			if tt.want == "" {
+				if got != "" {
+					t.Fatalf("unexpected warnings:\n%s", got)
+				}
+				return
+			}
+			if !strings.Contains(got, `field "Count" (int64) -> "Count" (int32): `+tt.want) {
+				t.Fatalf("warnings %q do not contain %q", got, tt.want)
+			}
+			if strings.Contains(got, `"Name"`) {
+				t.Fatalf("lossless field reported:\n%s", got)
+			}
+		})
+	}
+}
+
 type mockParser struct {
 	srcInfos []*parser.StructInfo
 	dstInfos []*parser.StructInfo
+	funcs    []parser.FuncInfo
 	srcErr   error
 	dstErr   error
 	calls    int
@@ -158,6 +367,18 @@ func (m *mockParser) ParseRecursive(pkgPath string, typeName string) ([]*parser.
 	return m.dstInfos, nil
 }
 
+func (m *mockParser) ParseFuncs(pkgPath string, excludeFile string) ([]parser.FuncInfo, error) {
+	return m.funcs, nil
+}
+
+func (m *mockParser) CheckSource(pkgPath string, src []byte) ([]parser.SourceError, error) {
+	return nil, nil
+}
+
+func (m *mockParser) ParseCollection(pkgPath string, typeName string) (*parser.CollectionInfo, error) {
+	return nil, nil
+}
+
 type mockStructMatcher struct {
 	pairs []matcher.StructPair
 }
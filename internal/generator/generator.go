@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"go/types"
 	"os"
 	"sort"
 	"strings"
@@ -11,6 +12,7 @@ import (
 
 	"golang.org/x/tools/imports"
 
+	"github.com/seitarof/gen-dto/internal/parser"
 	"github.com/seitarof/gen-dto/internal/resolver"
 )
 
@@ -54,16 +56,60 @@ type templateData struct {
 }
 
 type conversionTemplateData struct {
+	FuncName     string
+	SrcType      string
+	DstType      string
+	Plans        []resolver.ConversionPlan
+	Defaults     []resolver.DefaultValue
+	BeforeHook   *resolver.Hook
+	AfterHook    *resolver.Hook
+	ReturnsError bool
+	// Value, Method and Into select the converter shape; all false means
+	// func F(src *Src) *Dst. Patch marks Into functions applying patches.
+	Value  bool
+	Method bool
+	Into   bool
+	Patch  bool
+	// HookArgs passes the source and destination pointers to hooks.
+	HookArgs string
+	// ErrorReturn begins the statements that return an error.
+	ErrorReturn string
+	Collections []collectionTemplateData
+	// Sources are the parameters of a merged converter, whose SourceList
+	// names their types.
+	Sources    []mergeSourceTemplateData
+	SourceList string
+	// Params declares the extra parameters, e.g. "loc *time.Location", and
+	// ParamArgs passes them on, e.g. ", loc".
+	Params    string
+	ParamArgs string
+}
+
+type mergeSourceTemplateData struct {
+	Param string
+	Type  string
+	Plans []resolver.ConversionPlan
+	// Block reads the source as src when it is non-nil; sources whose
+	// plans are all skipped are not read.
+	Block bool
+}
+
+type collectionTemplateData struct {
 	FuncName string
 	SrcType  string
 	DstType  string
-	Plans    []resolver.ConversionPlan
+	// Generic makes the converter generic in the key type K of maps.
+	Generic      bool
+	Loop         string
+	ReturnsError bool
+	Params       string
 }
 
 // New creates a code generator.
 func New(f Formatter, w FileWriter) Generator {
 	tmpl := template.Must(template.New("").Funcs(template.FuncMap{
 		"renderPlan": renderPlan,
+		"renderHook": renderHook,
 	}).ParseFS(templateFS, "templates/*.go.tmpl"))
 	return &generatorImpl{formatter: f, writer: w, tmpl: tmpl}
 }
@@ -110,35 +156,66 @@ func (w *fileWriter) Write(filename string, data []byte) error {
 func buildTemplateData(plans []resolver.StructConversionPlan) templateData {
 	pkgName := plans[0].Src.PkgName
 	pkgPath := plans[0].Src.PkgPath
-	importsSet := map[string]struct{}{}
+	// importsSet maps the imported package paths to their names.
+	importsSet := map[string]string{}
 	conversions := make([]conversionTemplateData, 0, len(plans))
 
 	for _, p := range plans {
-		srcType := p.Src.Name
-		if p.Src.PkgPath != pkgPath {
-			srcType = p.Src.PkgName + "." + p.Src.Name
-			importsSet[p.Src.PkgPath] = struct{}{}
+		srcType := structTypeString(p.Src, pkgPath, importsSet)
+		dstType := structTypeString(p.Dst, pkgPath, importsSet)
+		for _, plan := range p.Plans {
+			recordFieldImports(plan, pkgPath, importsSet)
 		}
 
-		dstType := p.Dst.Name
-		if p.Dst.PkgPath != pkgPath {
-			importsSet[p.Dst.PkgPath] = struct{}{}
-			dstType = p.Dst.PkgName + "." + p.Dst.Name
+		sources, sourceList := mergeSources(p.Sources, pkgPath, importsSet)
+		hookArgs := "src, dst"
+		if p.Shape == resolver.FuncShapeValue {
+			hookArgs = "&src, &dst"
+		}
+		collections := make([]collectionTemplateData, 0, len(p.Collections))
+		for _, c := range p.Collections {
+			collections = append(collections, collectionTemplateData{
+				FuncName:     c.FuncName,
+				SrcType:      collectionTypeString(c.SrcNamed, srcType, c.SrcPtrElem, c.Map, pkgPath, importsSet),
+				DstType:      collectionTypeString(c.DstNamed, dstType, c.DstPtrElem, c.Map, pkgPath, importsSet),
+				Generic:      c.Map && c.SrcNamed == nil,
+				Loop:         renderStatements(c.Loop(dstType)),
+				ReturnsError: c.Elem.ReturnsError,
+				Params:       resolver.ParamDecls(c.Elem.Params),
+			})
 		}
-
 		conversions = append(conversions, conversionTemplateData{
-			FuncName: p.FuncName,
-			SrcType:  srcType,
-			DstType:  dstType,
-			Plans:    p.Plans,
+			FuncName:     p.FuncName,
+			SrcType:      srcType,
+			DstType:      dstType,
+			Plans:        p.Plans,
+			Defaults:     p.Defaults,
+			BeforeHook:   p.BeforeHook,
+			AfterHook:    p.AfterHook,
+			ReturnsError: p.ReturnsError,
+			Value:        p.Shape == resolver.FuncShapeValue,
+			Method:       p.Shape == resolver.FuncShapeMethod,
+			Into:         p.Shape == resolver.FuncShapeInto || p.Shape == resolver.FuncShapePatch,
+			Patch:        p.Shape == resolver.FuncShapePatch,
+			HookArgs:     hookArgs,
+			ErrorReturn:  p.Shape.ErrorReturn(dstType),
+			Collections:  collections,
+			Sources:      sources,
+			SourceList:   sourceList,
+			Params:       resolver.ParamDecls(p.Params),
+			ParamArgs:    paramArgs(p.Params),
 		})
 	}
 
-	importsList := make([]string, 0, len(importsSet))
+	paths := make([]string, 0, len(importsSet))
 	for path := range importsSet {
-		importsList = append(importsList, path)
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	importsList := make([]string, 0, len(paths))
+	for _, path := range paths {
+		importsList = append(importsList, parser.ImportSpec(path, importsSet[path]))
 	}
-	sort.Strings(importsList)
 
 	return templateData{
 		Package:     pkgName,
@@ -147,12 +224,122 @@ func buildTemplateData(plans []resolver.StructConversionPlan) templateData {
 	}
 }
 
+// mergeSources renders the sources of a merged converter and lists their
+// types, e.g. "User, Profile and Stats".
+func mergeSources(sources []resolver.MergeSource, pkgPath string, importsSet map[string]string) ([]mergeSourceTemplateData, string) {
+	if len(sources) == 0 {
+		return nil, ""
+	}
+	out := make([]mergeSourceTemplateData, 0, len(sources))
+	typeNames := make([]string, 0, len(sources))
+	for _, source := range sources {
+		data := mergeSourceTemplateData{
+			Param: source.Param,
+			Type:  structTypeString(source.Src, pkgPath, importsSet),
+			Plans: source.Plans,
+		}
+		for _, plan := range source.Plans {
+			recordFieldImports(plan, pkgPath, importsSet)
+			data.Block = data.Block || plan.Strategy != resolver.StrategySkip
+		}
+		out = append(out, data)
+		typeNames = append(typeNames, data.Type)
+	}
+	last := len(typeNames) - 1
+	return out, strings.Join(typeNames[:last], ", ") + " and " + typeNames[last]
+}
+
+// structTypeString renders info as seen from pkgPath and records the imports
+// it needs.
+func structTypeString(info *parser.StructInfo, pkgPath string, importsSet map[string]string) string {
+	if info.Type == nil {
+		if info.PkgPath == pkgPath {
+			return info.Name
+		}
+		importsSet[info.PkgPath] = info.PkgName
+		return parser.ImportName(info.PkgName) + "." + info.Name
+	}
+	return types.TypeString(info.Type, func(pkg *types.Package) string {
+		if pkg == nil || pkg.Path() == pkgPath {
+			return ""
+		}
+		importsSet[pkg.Path()] = pkg.Name()
+		return parser.ImportName(pkg.Name())
+	})
+}
+
+// collectionTypeString renders a named collection as seen from pkgPath, or a
+// slice or a map keyed by K of elem.
+func collectionTypeString(named *parser.CollectionInfo, elem string, ptrElem, isMap bool, pkgPath string, importsSet map[string]string) string {
+	if named != nil {
+		if named.PkgPath == pkgPath {
+			return named.Name
+		}
+		importsSet[named.PkgPath] = named.PkgName
+		return parser.ImportName(named.PkgName) + "." + named.Name
+	}
+	if ptrElem {
+		elem = "*" + elem
+	}
+	if isMap {
+		return "map[K]" + elem
+	}
+	return "[]" + elem
+}
+
+// recordFieldImports records the packages of the field types a plan converts,
+// so that constructors such as timestamppb.New resolve even where goimports
+// cannot find the package. goimports drops the ones left unused.
+func recordFieldImports(plan resolver.ConversionPlan, pkgPath string, importsSet map[string]string) {
+	if plan.Strategy == resolver.StrategySkip {
+		return
+	}
+	for _, t := range []types.Type{plan.SrcField.Type, plan.DstField.Type} {
+		if t == nil {
+			continue
+		}
+		types.TypeString(t, func(pkg *types.Package) string {
+			if pkg != nil && pkg.Path() != pkgPath {
+				importsSet[pkg.Path()] = pkg.Name()
+			}
+			return ""
+		})
+	}
+}
+
+// paramArgs passes params on after other arguments, e.g. ", loc".
+func paramArgs(params []resolver.Param) string {
+	var b strings.Builder
+	for _, p := range params {
+		b.WriteString(", " + p.Name)
+	}
+	return b.String()
+}
+
+// renderHook calls hook with args, followed by paramArgs when the hook takes
+// the extra parameters.
+func renderHook(hook *resolver.Hook, args, paramArgs, errorReturn string) string {
+	if hook.Params {
+		args += paramArgs
+	}
+	call := hook.Name + "(" + args + ")"
+	if hook.ReturnsError {
+		return "\tif err := " + call + "; err != nil {\n\t\t" + errorReturn + "err\n\t}"
+	}
+	return "\t" + call
+}
+
+// renderPlan indents plan's statements.
 func renderPlan(plan resolver.ConversionPlan) string {
 	if plan.Strategy == resolver.StrategySkip {
 		return renderSkipComment(plan)
 	}
 
-	snippet := strings.TrimSpace(plan.Expression)
+	return renderStatements(guardPointerHops(plan, strings.TrimSpace(plan.Expression)))
+}
+
+// renderStatements indents the lines of snippet.
+func renderStatements(snippet string) string {
 	if snippet == "" {
 		return ""
 	}
@@ -179,6 +366,27 @@ func renderPlan(plan resolver.ConversionPlan) string {
 	return b.String()
 }
 
+// guardPointerHops wraps snippet so that nil pointers on the source path are
+// not dereferenced and nil pointers on the destination path are allocated.
+func guardPointerHops(plan resolver.ConversionPlan, snippet string) string {
+	if snippet == "" {
+		return ""
+	}
+	for i := len(plan.DstField.PointerHops) - 1; i >= 0; i-- {
+		hop := plan.DstField.PointerHops[i]
+		sel := "dst." + hop.AccessPath
+		snippet = "if " + sel + " == nil {\n" + sel + " = new(" + hop.TypeStr + ")\n}\n" + snippet
+	}
+	if len(plan.SrcField.PointerHops) == 0 {
+		return snippet
+	}
+	conds := make([]string, 0, len(plan.SrcField.PointerHops))
+	for _, hop := range plan.SrcField.PointerHops {
+		conds = append(conds, "src."+hop.AccessPath+" != nil")
+	}
+	return "if " + strings.Join(conds, " && ") + " {\n" + snippet + "\n}"
+}
+
 func renderSkipComment(plan resolver.ConversionPlan) string {
 	dstName := plan.DstField.Name
 	if dstName == "" {
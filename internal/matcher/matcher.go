@@ -1,7 +1,9 @@
 package matcher
 
 import (
+	"go/types"
 	"slices"
+	"sort"
 	"strings"
 
 	"github.com/seitarof/gen-dto/internal/parser"
@@ -29,9 +31,37 @@ type FieldMatcher interface {
 	Match(src, dst *parser.StructInfo, ignoreFields []string) []FieldPair
 }
 
+// FieldOptions configures path-based field matching.
+type FieldOptions struct {
+	// MatchPaths pairs a flat field with a nested one when their names
+	// concatenate to the same name, e.g. AddressCity and Address.City.
+	MatchPaths bool
+	// Mappings pairs fields explicitly by access path.
+	Mappings []PathMapping
+	// EmbeddedUnits pairs an embedded struct with the other side's embedded
+	// or named field of the same name and struct type, instead of pairing
+	// its promoted fields one by one.
+	EmbeddedUnits bool
+	// Accessors reads through GetX() getters and writes through SetX(v)
+	// setters for names without a matching field.
+	Accessors bool
+	// Oneofs pairs the members of protobuf oneof variants with fields of the
+	// same name on the other side.
+	Oneofs bool
+}
+
+// PathMapping pairs the field at one access path with the field at another,
+// e.g. Address.City with AddressCity. It applies in both directions.
+type PathMapping struct {
+	From string
+	To   string
+}
+
 type structMatcherImpl struct{}
 
-type fieldMatcherImpl struct{}
+type fieldMatcherImpl struct {
+	opts FieldOptions
+}
 
 // NewStructMatcher returns default struct matcher.
 func NewStructMatcher() StructMatcher {
@@ -40,7 +70,12 @@ func NewStructMatcher() StructMatcher {
 
 // NewFieldMatcher returns default field matcher.
 func NewFieldMatcher() FieldMatcher {
-	return &fieldMatcherImpl{}
+	return NewFieldMatcherWithOptions(FieldOptions{})
+}
+
+// NewFieldMatcherWithOptions returns a field matcher configured by opts.
+func NewFieldMatcherWithOptions(opts FieldOptions) FieldMatcher {
+	return &fieldMatcherImpl{opts: opts}
 }
 
 func (m *structMatcherImpl) MatchStructs(srcInfos, dstInfos []*parser.StructInfo) []StructPair {
@@ -80,9 +115,294 @@ func (m *fieldMatcherImpl) Match(src, dst *parser.StructInfo, ignoreFields []str
 		}
 		pairs = append(pairs, FieldPair{SrcField: sf, DstField: df})
 	}
+
+	if m.opts.EmbeddedUnits {
+		pairs = matchEmbeddedUnits(pairs, src, dst, ignoreSet)
+	}
+	if m.opts.Accessors {
+		pairs = matchAccessors(pairs, src, dst, ignoreSet)
+	}
+	if m.opts.Oneofs {
+		pairs = matchOneofs(pairs, src, dst, ignoreSet)
+	}
+	if m.opts.MatchPaths {
+		pairs = matchPaths(pairs, src, dst, ignoreSet)
+	}
+	for _, mapping := range m.opts.Mappings {
+		pairs = applyMapping(pairs, src, dst, mapping)
+	}
 	return pairs
 }
 
+// matchEmbeddedUnits replaces the promoted-field pairs of an embedded struct
+// with a single pair for the embedded field when the other side has an
+// embedded or named counterpart of the same struct type.
+func matchEmbeddedUnits(pairs []FieldPair, src, dst *parser.StructInfo, ignoreSet map[string]bool) []FieldPair {
+	srcUnits := unitFields(src)
+	dstUnits := unitFields(dst)
+
+	var units []unitPair
+	for lower, su := range srcUnits {
+		du, ok := dstUnits[lower]
+		if !ok || ignoreSet[lower] || (!su.embedded && !du.embedded) || !sameStruct(su.field, du.field) {
+			continue
+		}
+		units = append(units, unitPair{src: su, dst: du})
+	}
+	if len(units) == 0 {
+		return pairs
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].dst.order < units[j].dst.order })
+
+	out := make([]FieldPair, 0, len(pairs)+len(units))
+	for _, p := range pairs {
+		if !coveredByUnit(p, units) {
+			out = append(out, p)
+		}
+	}
+	for _, u := range units {
+		out = append(out, FieldPair{SrcField: u.src.field, DstField: u.dst.field})
+	}
+	return out
+}
+
+type unitField struct {
+	field    parser.FieldInfo
+	embedded bool
+	order    int
+}
+
+type unitPair struct {
+	src, dst unitField
+}
+
+// unitFields indexes embedded structs and direct named fields by lower-case
+// name.
+func unitFields(info *parser.StructInfo) map[string]unitField {
+	out := make(map[string]unitField, len(info.Fields)+len(info.Embedded))
+	for i, f := range info.Fields {
+		if !strings.Contains(f.AccessPath, ".") {
+			out[strings.ToLower(f.Name)] = unitField{field: f, order: len(info.Embedded) + i}
+		}
+	}
+	for i, f := range info.Embedded {
+		out[strings.ToLower(f.Name)] = unitField{field: f, embedded: true, order: i}
+	}
+	return out
+}
+
+func coveredByUnit(p FieldPair, units []unitPair) bool {
+	for _, u := range units {
+		if u.src.embedded && strings.HasPrefix(p.SrcField.AccessPath, u.src.field.AccessPath+".") {
+			return true
+		}
+		if u.dst.embedded && strings.HasPrefix(p.DstField.AccessPath, u.dst.field.AccessPath+".") {
+			return true
+		}
+		if p.DstField.AccessPath == u.dst.field.AccessPath {
+			return true
+		}
+	}
+	return false
+}
+
+// sameStruct reports whether both fields hold structs that the struct matcher
+// pairs, i.e. structs with the same type name.
+func sameStruct(a, b parser.FieldInfo) bool {
+	an, aok := structName(a.Type)
+	bn, bok := structName(b.Type)
+	return aok && bok && an == bn
+}
+
+func structName(t types.Type) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return "", false
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return "", false
+	}
+	return named.Obj().Name(), true
+}
+
+// matchAccessors pairs source getters with unmatched destination fields, and
+// source fields or getters with destination setters whose name no field
+// pair writes yet.
+func matchAccessors(pairs []FieldPair, src, dst *parser.StructInfo, ignoreSet map[string]bool) []FieldPair {
+	matchedDst := make(map[string]bool, len(pairs))
+	for _, p := range pairs {
+		matchedDst[strings.ToLower(p.DstField.Name)] = true
+	}
+
+	getters := make(map[string]parser.FieldInfo, len(src.Getters))
+	for _, f := range src.Getters {
+		getters[strings.ToLower(f.Name)] = f
+	}
+	readers := make(map[string]parser.FieldInfo, len(src.Fields)+len(src.Getters))
+	for lower, f := range getters {
+		readers[lower] = f
+	}
+	for _, f := range src.Fields {
+		readers[strings.ToLower(f.Name)] = f
+	}
+
+	for _, df := range dst.Fields {
+		lower := strings.ToLower(df.Name)
+		if matchedDst[lower] || ignoreSet[lower] {
+			continue
+		}
+		if sf, ok := getters[lower]; ok {
+			pairs = append(pairs, FieldPair{SrcField: sf, DstField: df})
+			matchedDst[lower] = true
+		}
+	}
+	for _, df := range dst.Setters {
+		lower := strings.ToLower(df.Name)
+		if matchedDst[lower] || ignoreSet[lower] {
+			continue
+		}
+		if sf, ok := readers[lower]; ok {
+			pairs = append(pairs, FieldPair{SrcField: sf, DstField: df})
+			matchedDst[lower] = true
+		}
+	}
+	return pairs
+}
+
+// matchOneofs pairs source oneof variants with unmatched destination fields,
+// and source fields or variants with destination variants.
+func matchOneofs(pairs []FieldPair, src, dst *parser.StructInfo, ignoreSet map[string]bool) []FieldPair {
+	matchedDst := make(map[string]bool, len(pairs))
+	for _, p := range pairs {
+		matchedDst[strings.ToLower(p.DstField.Name)] = true
+	}
+
+	variants := make(map[string]parser.FieldInfo, len(src.Oneofs))
+	for _, f := range src.Oneofs {
+		variants[strings.ToLower(f.Name)] = f
+	}
+	readers := make(map[string]parser.FieldInfo, len(src.Fields)+len(src.Oneofs))
+	for lower, f := range variants {
+		readers[lower] = f
+	}
+	for _, f := range src.Fields {
+		readers[strings.ToLower(f.Name)] = f
+	}
+
+	for _, df := range dst.Fields {
+		lower := strings.ToLower(df.Name)
+		if matchedDst[lower] || ignoreSet[lower] {
+			continue
+		}
+		if sf, ok := variants[lower]; ok {
+			pairs = append(pairs, FieldPair{SrcField: sf, DstField: df})
+			matchedDst[lower] = true
+		}
+	}
+	for _, df := range dst.Oneofs {
+		lower := strings.ToLower(df.Name)
+		if matchedDst[lower] || ignoreSet[lower] {
+			continue
+		}
+		if sf, ok := readers[lower]; ok {
+			pairs = append(pairs, FieldPair{SrcField: sf, DstField: df})
+			matchedDst[lower] = true
+		}
+	}
+	return pairs
+}
+
+// matchPaths flattens nested source fields into unmatched destination fields
+// and unflattens unmatched source fields into nested destination fields.
+func matchPaths(pairs []FieldPair, src, dst *parser.StructInfo, ignoreSet map[string]bool) []FieldPair {
+	matchedSrc := make(map[string]bool, len(pairs))
+	matchedDst := make(map[string]bool, len(pairs))
+	for _, p := range pairs {
+		matchedSrc[p.SrcField.AccessPath] = true
+		matchedDst[p.DstField.AccessPath] = true
+	}
+
+	srcPaths := make(map[string]parser.FieldInfo, len(src.PathFields))
+	for _, f := range src.PathFields {
+		srcPaths[strings.ToLower(f.Name)] = f
+	}
+	for _, df := range dst.Fields {
+		lower := strings.ToLower(df.Name)
+		if matchedDst[df.AccessPath] || ignoreSet[lower] {
+			continue
+		}
+		sf, ok := srcPaths[lower]
+		if !ok {
+			continue
+		}
+		pairs = append(pairs, FieldPair{SrcField: sf, DstField: df})
+		matchedDst[df.AccessPath] = true
+	}
+
+	srcFlat := make(map[string]parser.FieldInfo, len(src.Fields))
+	for _, f := range src.Fields {
+		srcFlat[strings.ToLower(f.Name)] = f
+	}
+	for _, df := range dst.PathFields {
+		lower := strings.ToLower(df.Name)
+		if matchedDst[rootSegment(df.AccessPath)] || ignoreSet[lower] {
+			continue
+		}
+		sf, ok := srcFlat[lower]
+		if !ok || matchedSrc[sf.AccessPath] {
+			continue
+		}
+		pairs = append(pairs, FieldPair{SrcField: sf, DstField: df})
+		matchedSrc[sf.AccessPath] = true
+	}
+	return pairs
+}
+
+// applyMapping pairs the fields named by mapping in whichever direction both
+// exist, replacing any pair that already writes the destination field.
+func applyMapping(pairs []FieldPair, src, dst *parser.StructInfo, mapping PathMapping) []FieldPair {
+	sf, okSrc := findPath(src, mapping.From)
+	df, okDst := findPath(dst, mapping.To)
+	if !okSrc || !okDst {
+		sf, okSrc = findPath(src, mapping.To)
+		df, okDst = findPath(dst, mapping.From)
+	}
+	if !okSrc || !okDst {
+		return pairs
+	}
+
+	out := pairs[:0]
+	for _, p := range pairs {
+		if p.DstField.AccessPath != df.AccessPath {
+			out = append(out, p)
+		}
+	}
+	return append(out, FieldPair{SrcField: sf, DstField: df})
+}
+
+func findPath(info *parser.StructInfo, path string) (parser.FieldInfo, bool) {
+	path = strings.TrimSpace(path)
+	for _, fields := range [][]parser.FieldInfo{info.Fields, info.PathFields} {
+		for _, f := range fields {
+			if strings.EqualFold(f.AccessPath, path) {
+				return f, true
+			}
+		}
+	}
+	return parser.FieldInfo{}, false
+}
+
+func rootSegment(path string) string {
+	root, _, _ := strings.Cut(path, ".")
+	return root
+}
+
 func toIgnoreSet(ignoreFields []string) map[string]bool {
 	set := make(map[string]bool, len(ignoreFields))
 	for _, f := range ignoreFields {
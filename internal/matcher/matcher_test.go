@@ -45,3 +45,80 @@ func TestStructMatcher_MatchStructs_ExactName(t *testing.T) {
 		t.Fatalf("unexpected pair: %#v", pairs[0])
 	}
 }
+
+func TestFieldMatcher_Match_PathsAndMappings(t *testing.T) {
+	src := &parser.StructInfo{
+		Name: "User",
+		Fields: []parser.FieldInfo{
+			{Name: "ID", AccessPath: "ID"},
+			{Name: "Address", AccessPath: "Address"},
+		},
+		PathFields: []parser.FieldInfo{
+			{Name: "AddressCity", AccessPath: "Address.City"},
+			{Name: "AddressZip", AccessPath: "Address.Zip"},
+		},
+	}
+	dst := &parser.StructInfo{
+		Name: "UserDTO",
+		Fields: []parser.FieldInfo{
+			{Name: "ID", AccessPath: "ID"},
+			{Name: "AddressCity", AccessPath: "AddressCity"},
+			{Name: "PostalCode", AccessPath: "PostalCode"},
+		},
+	}
+
+	if pairs := NewFieldMatcher().Match(src, dst, nil); len(pairs) != 1 {
+		t.Fatalf("expected path matching to be opt-in, got %#v", pairs)
+	}
+
+	m := NewFieldMatcherWithOptions(FieldOptions{
+		MatchPaths: true,
+		Mappings:   []PathMapping{{From: "Address.Zip", To: "PostalCode"}},
+	})
+	pairs := m.Match(src, dst, nil)
+	if len(pairs) != 3 {
+		t.Fatalf("expected 3 pairs, got %#v", pairs)
+	}
+	if pairs[1].SrcField.AccessPath != "Address.City" || pairs[1].DstField.Name != "AddressCity" {
+		t.Fatalf("unexpected flattened pair: %#v", pairs[1])
+	}
+	if pairs[2].SrcField.AccessPath != "Address.Zip" || pairs[2].DstField.Name != "PostalCode" {
+		t.Fatalf("unexpected mapped pair: %#v", pairs[2])
+	}
+
+	reversed := m.Match(dst, src, nil)
+	if len(reversed) != 3 {
+		t.Fatalf("expected 3 reversed pairs, got %#v", reversed)
+	}
+	if reversed[2].SrcField.Name != "PostalCode" || reversed[2].DstField.AccessPath != "Address.Zip" {
+		t.Fatalf("unexpected reversed mapping: %#v", reversed[2])
+	}
+}
+
+func TestFieldMatcher_Match_Accessors(t *testing.T) {
+	src := &parser.StructInfo{
+		Name:    "Order",
+		Fields:  []parser.FieldInfo{{Name: "ID", AccessPath: "ID"}},
+		Getters: []parser.FieldInfo{{Name: "ID", AccessPath: "GetID()"}, {Name: "Total", AccessPath: "GetTotal()"}},
+	}
+	dst := &parser.StructInfo{
+		Name:    "Order",
+		Fields:  []parser.FieldInfo{{Name: "Total", AccessPath: "Total"}},
+		Setters: []parser.FieldInfo{{Name: "ID", AccessPath: "SetID"}, {Name: "Total", AccessPath: "SetTotal"}},
+	}
+
+	if pairs := NewFieldMatcher().Match(src, dst, nil); len(pairs) != 0 {
+		t.Fatalf("expected accessors to be opt-in, got %#v", pairs)
+	}
+
+	pairs := NewFieldMatcherWithOptions(FieldOptions{Accessors: true}).Match(src, dst, nil)
+	if len(pairs) != 2 {
+		t.Fatalf("expected 2 pairs, got %#v", pairs)
+	}
+	if pairs[0].SrcField.AccessPath != "GetTotal()" || pairs[0].DstField.AccessPath != "Total" {
+		t.Fatalf("unexpected getter pair: %#v", pairs[0])
+	}
+	if pairs[1].SrcField.AccessPath != "ID" || pairs[1].DstField.AccessPath != "SetID" {
+		t.Fatalf("fields should win over getters for setters: %#v", pairs[1])
+	}
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"go/types"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// accessorFields records GetX() T methods as getters and SetX(T) methods as
+// setters, both named X, in declaration order so setters are called in the
+// order their type declares them. Methods of *T are included since
+// converters receive and build pointers.
+func accessorFields(t types.Type, qualifier types.Qualifier) (getters, setters []FieldInfo) {
+	if _, ok := types.Unalias(t).(*types.Named); !ok {
+		return nil, nil
+	}
+	ms := types.NewMethodSet(types.NewPointer(t))
+	methods := make([]*types.Func, 0, ms.Len())
+	for i := 0; i < ms.Len(); i++ {
+		if fn, ok := ms.At(i).Obj().(*types.Func); ok && fn.Exported() {
+			methods = append(methods, fn)
+		}
+	}
+	sort.SliceStable(methods, func(i, j int) bool { return methods[i].Pos() < methods[j].Pos() })
+	for _, fn := range methods {
+		sig, ok := fn.Type().(*types.Signature)
+		if !ok || sig.Variadic() {
+			continue
+		}
+		if name, ok := accessorName(fn.Name(), "Get"); ok && sig.Params().Len() == 0 && sig.Results().Len() == 1 {
+			getters = append(getters, accessorField(name, fn.Name()+"()", FieldAccessGetter, sig.Results().At(0).Type(), qualifier))
+		}
+		if name, ok := accessorName(fn.Name(), "Set"); ok && sig.Params().Len() == 1 && sig.Results().Len() == 0 {
+			setters = append(setters, accessorField(name, fn.Name(), FieldAccessSetter, sig.Params().At(0).Type(), qualifier))
+		}
+	}
+	return getters, setters
+}
+
+// accessorName strips prefix from GetName-style method names. The rest must
+// start with an upper-case letter, so Settle is not a setter for "tle".
+func accessorName(method, prefix string) (string, bool) {
+	if len(method) <= len(prefix) || method[:len(prefix)] != prefix {
+		return "", false
+	}
+	rest := method[len(prefix):]
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest, unicode.IsUpper(r)
+}
+
+func accessorField(name, accessPath string, access FieldAccess, t types.Type, qualifier types.Qualifier) FieldInfo {
+	return FieldInfo{
+		Name:       name,
+		AccessPath: accessPath,
+		TypeStr:    types.TypeString(t, qualifier),
+		TypeInfo:   analyzeType(t),
+		Type:       t,
+		IsExported: true,
+		Access:     access,
+	}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// checkFilename names the overlay file type-checked by CheckSource.
+const checkFilename = "gen_dto_check.go"
+
+// SourceError is a type error found in the source given to CheckSource.
+type SourceError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e SourceError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// CheckSource type-checks src as an additional file of the package at pkgPath
+// without writing it, and returns the errors located in src. Errors in the
+// package's own files, such as a stale generated file, are ignored.
+func (p *parserImpl) CheckSource(pkgPath string, src []byte) ([]SourceError, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles}, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("load package %q: %w", pkgPath, err)
+	}
+	if len(pkgs) == 0 || len(pkgs[0].GoFiles) == 0 {
+		return nil, fmt.Errorf("package %q not found", pkgPath)
+	}
+	filename := filepath.Join(filepath.Dir(pkgs[0].GoFiles[0]), checkFilename)
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedCompiledGoFiles |
+			packages.NeedTypes |
+			packages.NeedSyntax |
+			packages.NeedTypesInfo,
+		Overlay: map[string][]byte{filename: src},
+	}
+	pkgs, err = packages.Load(cfg, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("load package %q: %w", pkgPath, err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("package %q not found", pkgPath)
+	}
+
+	var errs []SourceError
+	for _, e := range pkgs[0].Errors {
+		rest, ok := strings.CutPrefix(e.Pos, filename+":")
+		if !ok {
+			continue
+		}
+		line, col, _ := strings.Cut(rest, ":")
+		se := SourceError{Msg: e.Msg}
+		se.Line, _ = strconv.Atoi(line)
+		se.Column, _ = strconv.Atoi(col)
+		errs = append(errs, se)
+	}
+	return errs, nil
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// CollectionInfo describes a named slice or map of structs, e.g.
+// type Users []User or type UsersByID map[string]*User.
+type CollectionInfo struct {
+	Name    string
+	PkgPath string
+	PkgName string
+	// Map is set for map types; keys are copied as they are.
+	Map bool
+	// Elem names the element struct declared in ElemPkgPath. PtrElem reports
+	// elements of type *Elem.
+	Elem        string
+	ElemPkgPath string
+	PtrElem     bool
+}
+
+// ParseCollection returns the named slice or map of structs typeName of the
+// package at pkgPath, or nil when typeName is another kind of type.
+func (p *parserImpl) ParseCollection(pkgPath string, typeName string) (*CollectionInfo, error) {
+	if strings.Contains(typeName, "[") {
+		return nil, nil
+	}
+	pkg, err := p.loadPackage(pkgPath, map[string]*packages.Package{})
+	if err != nil {
+		return nil, err
+	}
+	if pkg.Types == nil || pkg.Types.Scope() == nil {
+		return nil, fmt.Errorf("type info unavailable for package %q", pkgPath)
+	}
+	obj, ok := pkg.Types.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, nil
+	}
+
+	info := &CollectionInfo{Name: typeName, PkgPath: pkg.Types.Path(), PkgName: pkg.Types.Name()}
+	var elem types.Type
+	switch u := obj.Type().Underlying().(type) {
+	case *types.Slice:
+		elem = u.Elem()
+	case *types.Map:
+		info.Map = true
+		elem = u.Elem()
+	default:
+		return nil, nil
+	}
+	if ptr, ok := types.Unalias(elem).(*types.Pointer); ok {
+		info.PtrElem = true
+		elem = ptr.Elem()
+	}
+	named, ok := types.Unalias(elem).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.TypeArgs().Len() > 0 {
+		return nil, fmt.Errorf("%q in package %q is not a collection of named structs", typeName, pkgPath)
+	}
+	if _, ok := extractStructType(named); !ok {
+		return nil, fmt.Errorf("%q in package %q is not a collection of named structs", typeName, pkgPath)
+	}
+	info.Elem = named.Obj().Name()
+	info.ElemPkgPath = named.Obj().Pkg().Path()
+	return info, nil
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"sort"
 	"strings"
 
@@ -8,23 +9,44 @@ import (
 )
 
 type fieldCandidate struct {
-	field     FieldInfo
-	depth     int
-	order     int
-	ambiguous bool
+	field FieldInfo
+	depth int
+	order int
+	// rivals holds other fields of the same name at the same depth.
+	rivals []FieldInfo
 }
 
-func flattenFields(st *types.Struct, qualifier types.Qualifier) []FieldInfo {
+// flattenOptions configures flattenFields.
+type flattenOptions struct {
+	qualifier types.Qualifier
+	// preferred holds lower-case access paths that win ambiguous names.
+	preferred map[string]bool
+	// unexported includes unexported fields.
+	unexported bool
+}
+
+// flattenFields lists the fields of st with promoted fields inlined. A name
+// promoted from several embedded structs at the same depth is ambiguous, as
+// in Go; it is kept only when one of its access paths is preferred, and
+// reported otherwise.
+func flattenFields(st *types.Struct, opts flattenOptions) ([]FieldInfo, []AmbiguousField) {
 	candidates := map[string]fieldCandidate{}
 	order := 0
-	collectFlattenedFields(st, nil, "", 0, qualifier, candidates, &order)
+	collectFlattenedFields(st, nil, nil, "", "", 0, opts, candidates, &order)
 
 	sorted := make([]fieldCandidate, 0, len(candidates))
+	var dropped []fieldCandidate
 	for _, cand := range candidates {
-		if cand.ambiguous {
+		if len(cand.rivals) == 0 {
+			sorted = append(sorted, cand)
 			continue
 		}
-		sorted = append(sorted, cand)
+		if winner, ok := preferredField(append([]FieldInfo{cand.field}, cand.rivals...), opts.preferred); ok {
+			cand.field = winner
+			sorted = append(sorted, cand)
+			continue
+		}
+		dropped = append(dropped, cand)
 	}
 
 	sort.Slice(sorted, func(i, j int) bool {
@@ -38,15 +60,36 @@ func flattenFields(st *types.Struct, qualifier types.Qualifier) []FieldInfo {
 	for _, cand := range sorted {
 		fields = append(fields, cand.field)
 	}
-	return fields
+
+	sort.Slice(dropped, func(i, j int) bool { return dropped[i].order < dropped[j].order })
+	var ambiguous []AmbiguousField
+	for _, cand := range dropped {
+		paths := []string{cand.field.AccessPath}
+		for _, f := range cand.rivals {
+			paths = append(paths, f.AccessPath)
+		}
+		ambiguous = append(ambiguous, AmbiguousField{Name: cand.field.Name, AccessPaths: paths})
+	}
+	return fields, ambiguous
+}
+
+func preferredField(fields []FieldInfo, preferred map[string]bool) (FieldInfo, bool) {
+	for _, f := range fields {
+		if preferred[strings.ToLower(f.AccessPath)] {
+			return f, true
+		}
+	}
+	return FieldInfo{}, false
 }
 
 func collectFlattenedFields(
 	st *types.Struct,
 	prefix []string,
+	hops []PointerHop,
 	embedFrom string,
+	accessPkg string,
 	depth int,
-	qualifier types.Qualifier,
+	opts flattenOptions,
 	out map[string]fieldCandidate,
 	order *int,
 ) {
@@ -58,6 +101,14 @@ func collectFlattenedFields(
 				continue
 			}
 			nextPrefix := appendPath(prefix, f.Name())
+			nextHops := hops
+			if ptr, ok := types.Unalias(f.Type()).(*types.Pointer); ok {
+				nextHops = appendHop(hops, PointerHop{
+					AccessPath: strings.Join(nextPrefix, "."),
+					TypeStr:    types.TypeString(ptr.Elem(), opts.qualifier),
+					Type:       ptr.Elem(),
+				})
+			}
 			nextEmbedFrom := embeddedName
 			if nextEmbedFrom == "" {
 				nextEmbedFrom = f.Name()
@@ -65,26 +116,32 @@ func collectFlattenedFields(
 			collectFlattenedFields(
 				embeddedStruct,
 				nextPrefix,
+				nextHops,
 				nextEmbedFrom,
+				restrictAccess(accessPkg, f),
 				depth+1,
-				qualifier,
+				opts,
 				out,
 				order,
 			)
 			continue
 		}
-		if !f.Exported() {
+		if !f.Exported() && !opts.unexported {
 			continue
 		}
 
+		fieldAccessPkg := restrictAccess(accessPkg, f)
 		field := FieldInfo{
-			Name:       f.Name(),
-			AccessPath: buildAccessPath(prefix, f.Name()),
-			TypeStr:    types.TypeString(f.Type(), qualifier),
-			TypeInfo:   analyzeType(f.Type()),
-			Type:       f.Type(),
-			IsExported: true,
-			EmbedFrom:  embedFrom,
+			Name:        f.Name(),
+			AccessPath:  buildAccessPath(prefix, f.Name()),
+			TypeStr:     types.TypeString(f.Type(), opts.qualifier),
+			TypeInfo:    analyzeType(f.Type()),
+			Type:        f.Type(),
+			IsExported:  fieldAccessPkg == "",
+			AccessPkg:   fieldAccessPkg,
+			EmbedFrom:   embedFrom,
+			PointerHops: hops,
+			Default:     tagDefault(st.Tag(i)),
 		}
 		addCandidate(out, field, depth, order)
 	}
@@ -109,11 +166,42 @@ func addCandidate(out map[string]fieldCandidate, field FieldInfo, depth int, ord
 	}
 
 	if cand.field.AccessPath != field.AccessPath {
-		cand.ambiguous = true
+		cand.rivals = append(cand.rivals, field)
 		out[key] = cand
 	}
 }
 
+// restrictAccess returns the package that must host code selecting f through
+// a path already restricted to accessPkg.
+func restrictAccess(accessPkg string, f *types.Var) string {
+	if f.Exported() || f.Pkg() == nil {
+		return accessPkg
+	}
+	return f.Pkg().Path()
+}
+
+func embeddedFields(st *types.Struct, qualifier types.Qualifier) []FieldInfo {
+	var out []FieldInfo
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Embedded() || !f.Exported() {
+			continue
+		}
+		if embeddedStruct, _ := resolveEmbeddedStruct(f.Type()); embeddedStruct == nil {
+			continue
+		}
+		out = append(out, FieldInfo{
+			Name:       f.Name(),
+			AccessPath: f.Name(),
+			TypeStr:    types.TypeString(f.Type(), qualifier),
+			TypeInfo:   analyzeType(f.Type()),
+			Type:       f.Type(),
+			IsExported: true,
+		})
+	}
+	return out
+}
+
 func appendPath(prefix []string, part string) []string {
 	next := make([]string, 0, len(prefix)+1)
 	next = append(next, prefix...)
@@ -142,3 +230,12 @@ func resolveEmbeddedStruct(t types.Type) (*types.Struct, string) {
 	}
 	return nil, ""
 }
+
+// tagDefault returns the expression of a dto:"default=..." tag.
+func tagDefault(tag string) string {
+	value, ok := strings.CutPrefix(reflect.StructTag(tag).Get("dto"), "default=")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// FuncInfo describes one package-level function declared in a package.
+type FuncInfo struct {
+	Name      string
+	Signature *types.Signature
+}
+
+func (p *parserImpl) ParseFuncs(pkgPath string, excludeFile string) ([]FuncInfo, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedCompiledGoFiles |
+			packages.NeedTypes |
+			packages.NeedSyntax |
+			packages.NeedTypesInfo,
+	}
+
+	pkgs, err := packages.Load(cfg, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("load package %q: %w", pkgPath, err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("package %q not found", pkgPath)
+	}
+
+	// Type errors are tolerated here: a stale generated file may redeclare
+	// functions that are now written by hand.
+	pkg := pkgs[0]
+	if pkg.TypesInfo == nil || pkg.Fset == nil {
+		return nil, fmt.Errorf("type info unavailable for package %q", pkgPath)
+	}
+
+	if excludeFile != "" {
+		if abs, err := filepath.Abs(excludeFile); err == nil {
+			excludeFile = abs
+		}
+	}
+
+	funcs := []FuncInfo{}
+	for _, file := range pkg.Syntax {
+		filename := pkg.Fset.Position(file.Pos()).Filename
+		if excludeFile != "" && filepath.Clean(filename) == excludeFile {
+			continue
+		}
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil {
+				continue
+			}
+			fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			sig, ok := fn.Type().(*types.Signature)
+			if !ok {
+				continue
+			}
+			funcs = append(funcs, FuncInfo{Name: fn.Name(), Signature: sig})
+		}
+	}
+	return funcs, nil
+}
+
+// PackageScope returns the scope of the package at pkgPath. Packages known
+// only through the export data of their importers hold just the declarations
+// those importers reference; this scope holds every declaration.
+func PackageScope(pkgPath string) (*types.Scope, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes}, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("load package %q: %w", pkgPath, err)
+	}
+	if len(pkgs) == 0 || pkgs[0].Types == nil {
+		return nil, fmt.Errorf("package %q not found", pkgPath)
+	}
+	return pkgs[0].Types.Scope(), nil
+}
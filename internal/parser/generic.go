@@ -0,0 +1,42 @@
+package parser
+
+import "go/types"
+
+// instanceName names an instantiated generic type by its unqualified type
+// string, e.g. Page[User]. It identifies the instantiation when matching
+// structs and naming converters.
+func instanceName(named *types.Named) string {
+	return types.TypeString(named, func(*types.Package) string { return "" })
+}
+
+func typeArgDetails(named *types.Named) []TypeDetail {
+	args := named.TypeArgs()
+	if args.Len() == 0 {
+		return nil
+	}
+	details := make([]TypeDetail, 0, args.Len())
+	for i := 0; i < args.Len(); i++ {
+		details = append(details, analyzeType(args.At(i)))
+	}
+	return details
+}
+
+// instantiatedStruct unwraps pointers and slices down to an instantiated
+// generic struct.
+func instantiatedStruct(t types.Type) (*types.Named, bool) {
+	switch v := types.Unalias(t).(type) {
+	case *types.Pointer:
+		return instantiatedStruct(v.Elem())
+	case *types.Slice:
+		return instantiatedStruct(v.Elem())
+	case *types.Named:
+		if v.TypeArgs().Len() == 0 {
+			return nil, false
+		}
+		if _, ok := v.Underlying().(*types.Struct); !ok {
+			return nil, false
+		}
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,24 @@
+package parser
+
+import "strconv"
+
+// ImportName returns the name generated code refers to a package named name
+// by. Packages named like the src and dst variables of converters would be
+// shadowed by them, so they are imported as srcpkg and dstpkg.
+func ImportName(name string) string {
+	switch name {
+	case "src", "dst":
+		return name + "pkg"
+	default:
+		return name
+	}
+}
+
+// ImportSpec renders the import of the package at path named name, e.g.
+// `"time"` or `dstpkg "example.com/dst"`.
+func ImportSpec(path, name string) string {
+	if alias := ImportName(name); alias != name {
+		return alias + " " + strconv.Quote(path)
+	}
+	return strconv.Quote(path)
+}
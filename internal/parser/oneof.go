@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"go/types"
+	"reflect"
+)
+
+// oneofFields finds protoc-gen-go oneof fields, tagged protobuf_oneof and
+// typed by an interface, and lists the members of the variant structs that
+// implement it in the declaring package.
+func oneofFields(st *types.Struct, qualifier types.Qualifier) []FieldInfo {
+	var out []FieldInfo
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Exported() || reflect.StructTag(st.Tag(i)).Get("protobuf_oneof") == "" {
+			continue
+		}
+		named, ok := types.Unalias(f.Type()).(*types.Named)
+		if !ok || named.Obj().Pkg() == nil {
+			continue
+		}
+		iface, ok := named.Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+
+		scope := named.Obj().Pkg().Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+			variant, ok := obj.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			vst, ok := variant.Underlying().(*types.Struct)
+			if !ok || vst.NumFields() != 1 || !vst.Field(0).Exported() {
+				continue
+			}
+			if !types.Implements(types.NewPointer(variant), iface) {
+				continue
+			}
+			member := vst.Field(0)
+			out = append(out, FieldInfo{
+				Name:       member.Name(),
+				AccessPath: f.Name(),
+				TypeStr:    types.TypeString(member.Type(), qualifier),
+				TypeInfo:   analyzeType(member.Type()),
+				Type:       member.Type(),
+				IsExported: true,
+				Access:     FieldAccessOneof,
+				Oneof: &OneofVariant{
+					Field:      f.Name(),
+					WrapperStr: types.TypeString(variant, qualifier),
+					Wrapper:    variant,
+					Member:     member.Name(),
+				},
+			})
+		}
+	}
+	return out
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"strings"
 
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/packages"
@@ -14,13 +15,41 @@ import (
 type Parser interface {
 	Parse(pkgPath string, typeName string) (*StructInfo, error)
 	ParseRecursive(pkgPath string, typeName string) ([]*StructInfo, error)
+	ParseFuncs(pkgPath string, excludeFile string) ([]FuncInfo, error)
+	CheckSource(pkgPath string, src []byte) ([]SourceError, error)
+	ParseCollection(pkgPath string, typeName string) (*CollectionInfo, error)
 }
 
-type parserImpl struct{}
+// Options configures how structs are parsed.
+type Options struct {
+	// EmbeddedUnits makes ParseRecursive also parse embedded struct types so
+	// embedded fields can be converted as a whole.
+	EmbeddedUnits bool
+	// PreferredPaths picks the winner among ambiguous promoted fields by
+	// access path, e.g. Audit.CreatedAt.
+	PreferredPaths []string
+	// Unexported includes unexported fields. Each field records in AccessPkg
+	// the package that may access it.
+	Unexported bool
+	// Accessors makes ParseRecursive also parse struct types read by getters
+	// and written by setters.
+	Accessors bool
+	// Protobuf records the variants of protobuf oneof fields in Oneofs.
+	Protobuf bool
+}
+
+type parserImpl struct {
+	opts Options
+}
 
 // New returns default parser.
 func New() Parser {
-	return &parserImpl{}
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions returns a parser configured by opts.
+func NewWithOptions(opts Options) Parser {
+	return &parserImpl{opts: opts}
 }
 
 func (p *parserImpl) Parse(pkgPath string, typeName string) (*StructInfo, error) {
@@ -42,6 +71,10 @@ func (p *parserImpl) parseWithCache(
 		return nil, fmt.Errorf("type info unavailable for package %q", pkgPath)
 	}
 
+	if strings.Contains(typeName, "[") {
+		return p.parseInstance(pkg.Types, typeName)
+	}
+
 	obj := pkg.Types.Scope().Lookup(typeName)
 	if obj == nil {
 		return nil, fmt.Errorf("struct %q not found in package %q", typeName, pkgPath)
@@ -51,23 +84,75 @@ func (p *parserImpl) parseWithCache(
 	if !ok {
 		return nil, fmt.Errorf("%q in package %q is not a struct type", typeName, pkgPath)
 	}
+	return p.newStructInfo(typeName, obj.Type(), st, pkg.Types), nil
+}
+
+// parseInstance parses an instantiated generic struct such as Page[User].
+// Type arguments are resolved in the package scope.
+func (p *parserImpl) parseInstance(pkg *types.Package, typeName string) (*StructInfo, error) {
+	tv, err := types.Eval(token.NewFileSet(), pkg, token.NoPos, typeName)
+	if err != nil {
+		return nil, fmt.Errorf("struct %q not found in package %q: %w", typeName, pkg.Path(), err)
+	}
+	if !tv.IsType() {
+		return nil, fmt.Errorf("%q in package %q is not a type", typeName, pkg.Path())
+	}
+	named, ok := types.Unalias(tv.Type).(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%q in package %q is not a struct type", typeName, pkg.Path())
+	}
+	st, ok := extractStructType(named)
+	if !ok {
+		return nil, fmt.Errorf("%q in package %q is not a struct type", typeName, pkg.Path())
+	}
+	return p.newStructInfo(instanceName(named), named, st, named.Obj().Pkg()), nil
+}
 
+func (p *parserImpl) newStructInfo(name string, t types.Type, st *types.Struct, pkg *types.Package) *StructInfo {
 	qualifier := func(p *types.Package) string {
 		if p == nil {
 			return ""
 		}
-		if pkg.Types != nil && p.Path() == pkg.Types.Path() {
+		if p.Path() == pkg.Path() {
 			return ""
 		}
-		return p.Name()
+		return ImportName(p.Name())
 	}
 
-	return &StructInfo{
-		Name:    typeName,
-		PkgPath: pkg.Types.Path(),
-		PkgName: pkg.Name,
-		Fields:  flattenFields(st, qualifier),
-	}, nil
+	info := &StructInfo{
+		Name:    name,
+		PkgPath: pkg.Path(),
+		PkgName: pkg.Name(),
+		Pkg:     pkg,
+	}
+	if named, ok := types.Unalias(t).(*types.Named); ok && named.TypeArgs().Len() > 0 {
+		info.Type = named
+		info.TypeArgs = typeArgDetails(named)
+	}
+	opts := flattenOptions{
+		qualifier:  qualifier,
+		preferred:  p.preferredPaths(),
+		unexported: p.opts.Unexported,
+	}
+	info.Fields, info.Ambiguous = flattenFields(st, opts)
+	info.PathFields = collectPathFields(info.Fields, opts)
+	info.Embedded = embeddedFields(st, qualifier)
+	info.Getters, info.Setters = accessorFields(t, qualifier)
+	if p.opts.Protobuf {
+		info.Oneofs = oneofFields(st, qualifier)
+	}
+	return info
+}
+
+func (p *parserImpl) preferredPaths() map[string]bool {
+	if len(p.opts.PreferredPaths) == 0 {
+		return nil
+	}
+	preferred := make(map[string]bool, len(p.opts.PreferredPaths))
+	for _, path := range p.opts.PreferredPaths {
+		preferred[strings.ToLower(strings.TrimSpace(path))] = true
+	}
+	return preferred
 }
 
 func (p *parserImpl) loadPackage(pkgPath string, cache map[string]*packages.Package) (*packages.Package, error) {
@@ -127,14 +212,33 @@ func (p *parserImpl) parseRec(
 	if err != nil {
 		return err
 	}
+	return p.collectRec(info, visited, cache, rootModulePath, result)
+}
 
+func (p *parserImpl) collectRec(
+	info *StructInfo,
+	visited map[string]bool,
+	cache map[string]*packages.Package,
+	rootModulePath string,
+	result *[]*StructInfo,
+) error {
 	key := info.PkgPath + "." + info.Name
 	if visited[key] {
 		return nil
 	}
 	visited[key] = true
 
-	for _, f := range info.Fields {
+	fields := info.Fields
+	if p.opts.EmbeddedUnits {
+		fields = append(append([]FieldInfo{}, fields...), info.Embedded...)
+	}
+	if p.opts.Accessors {
+		fields = append(append(append([]FieldInfo{}, fields...), info.Getters...), info.Setters...)
+	}
+	if p.opts.Protobuf {
+		fields = append(append([]FieldInfo{}, fields...), info.Oneofs...)
+	}
+	for _, f := range fields {
 		nestedPkg, nestedName, ok := nestedStructRef(f.TypeInfo)
 		if !ok {
 			continue
@@ -145,6 +249,15 @@ func (p *parserImpl) parseRec(
 		if visited[nestedPkg+"."+nestedName] {
 			continue
 		}
+		// Instantiations cannot be looked up by name; parse the field type itself.
+		if named, ok := instantiatedStruct(f.Type); ok {
+			st, _ := extractStructType(named)
+			nested := p.newStructInfo(nestedName, named, st, named.Obj().Pkg())
+			if err := p.collectRec(nested, visited, cache, rootModulePath, result); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := p.parseRec(nestedPkg, nestedName, visited, cache, rootModulePath, result); err != nil {
 			log.Printf("gen-dto: warning: nested struct %q not found, skipped", nestedName)
 			continue
@@ -245,11 +358,16 @@ func analyzeType(t types.Type) TypeDetail {
 
 		switch under := v.Underlying().(type) {
 		case *types.Struct:
+			structName := obj.Name()
+			if v.TypeArgs().Len() > 0 {
+				structName = instanceName(v)
+			}
 			return TypeDetail{
 				Kind:       TypeKindStruct,
 				PkgPath:    pkgPath,
-				StructName: obj.Name(),
+				StructName: structName,
 				TypeName:   typeName,
+				TypeArgs:   typeArgDetails(v),
 			}
 		case *types.Basic:
 			return TypeDetail{
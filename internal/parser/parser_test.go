@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -83,10 +84,79 @@ func TestParse_EmbeddedAndConflict(t *testing.T) {
 	if id.AccessPath != "Base.ID" {
 		t.Fatalf("ID access path = %q, want Base.ID", id.AccessPath)
 	}
+	if len(id.PointerHops) != 0 {
+		t.Fatalf("ID pointer hops = %#v, want none for a value embed", id.PointerHops)
+	}
 
 	if fieldByName(info.Fields, "Code") != nil {
 		t.Fatal("Code should be dropped due to same-depth embedded conflict")
 	}
+	if len(info.Ambiguous) != 1 {
+		t.Fatalf("expected 1 ambiguous field, got %#v", info.Ambiguous)
+	}
+	if got := strings.Join(info.Ambiguous[0].AccessPaths, ","); info.Ambiguous[0].Name != "Code" || got != "InnerA.Code,InnerB.Code" {
+		t.Fatalf("unexpected ambiguous field: %#v", info.Ambiguous[0])
+	}
+}
+
+func TestParse_PreferredPathResolvesAmbiguousField(t *testing.T) {
+	p := NewWithOptions(Options{PreferredPaths: []string{"innerb.code"}})
+
+	info, err := p.Parse("github.com/seitarof/gen-dto/testdata/parserembed", "User")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	code := fieldByName(info.Fields, "Code")
+	if code == nil {
+		t.Fatal("Code field not found")
+	}
+	if code.AccessPath != "InnerB.Code" {
+		t.Fatalf("Code access path = %q, want InnerB.Code", code.AccessPath)
+	}
+	if len(info.Ambiguous) != 0 {
+		t.Fatalf("expected no ambiguous fields, got %#v", info.Ambiguous)
+	}
+}
+
+func TestParse_EmbeddedPointerRecordsPointerHop(t *testing.T) {
+	p := New()
+
+	info, err := p.Parse("github.com/seitarof/gen-dto/testdata/embedptr/model", "Record")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	createdBy := fieldByName(info.Fields, "CreatedBy")
+	if createdBy == nil {
+		t.Fatal("CreatedBy field not found")
+	}
+	if createdBy.AccessPath != "Audit.CreatedBy" {
+		t.Fatalf("CreatedBy access path = %q, want Audit.CreatedBy", createdBy.AccessPath)
+	}
+	if len(createdBy.PointerHops) != 1 || createdBy.PointerHops[0].AccessPath != "Audit" || createdBy.PointerHops[0].TypeStr != "Audit" {
+		t.Fatalf("unexpected pointer hops: %#v", createdBy.PointerHops)
+	}
+}
+
+func TestParse_UnexportedFieldsRecordAccessPackage(t *testing.T) {
+	p := NewWithOptions(Options{Unexported: true})
+
+	info, err := p.Parse("github.com/seitarof/gen-dto/testdata/parserbasic", "User")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	hidden := fieldByName(info.Fields, "hidden")
+	if hidden == nil {
+		t.Fatal("hidden field not found")
+	}
+	if hidden.IsExported || hidden.AccessPkg != "github.com/seitarof/gen-dto/testdata/parserbasic" {
+		t.Fatalf("unexpected access info: exported=%v pkg=%q", hidden.IsExported, hidden.AccessPkg)
+	}
+	if id := fieldByName(info.Fields, "ID"); id == nil || id.AccessPkg != "" {
+		t.Fatalf("exported field should be accessible everywhere: %#v", id)
+	}
 }
 
 func TestParse_TypeNotFound(t *testing.T) {
@@ -118,6 +188,89 @@ func TestParse_TypeAliasToStruct(t *testing.T) {
 	}
 }
 
+func TestParse_GenericInstance(t *testing.T) {
+	p := New()
+
+	info, err := p.Parse("github.com/seitarof/gen-dto/testdata/generics/model", "Page[User]")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if info.Name != "Page[User]" {
+		t.Fatalf("expected Name=Page[User], got %s", info.Name)
+	}
+	if len(info.TypeArgs) != 1 || info.TypeArgs[0].StructName != "User" {
+		t.Fatalf("unexpected type args: %#v", info.TypeArgs)
+	}
+
+	items := fieldByName(info.Fields, "Items")
+	if items == nil {
+		t.Fatal("Items field not found")
+	}
+	if items.TypeStr != "[]User" {
+		t.Fatalf("Items type = %q, want []User", items.TypeStr)
+	}
+
+	if _, err := p.Parse("github.com/seitarof/gen-dto/testdata/generics/model", "Page[Missing]"); err == nil {
+		t.Fatal("expected error for unknown type argument, got nil")
+	}
+}
+
+func TestParseCollection(t *testing.T) {
+	p := New()
+	const pkgPath = "github.com/seitarof/gen-dto/testdata/collections/model"
+
+	users, err := p.ParseCollection(pkgPath, "Users")
+	if err != nil {
+		t.Fatalf("ParseCollection() error = %v", err)
+	}
+	if users == nil || users.Map || users.PtrElem || users.Elem != "User" || users.ElemPkgPath != pkgPath {
+		t.Fatalf("unexpected Users collection: %#v", users)
+	}
+
+	byID, err := p.ParseCollection(pkgPath, "UsersByID")
+	if err != nil {
+		t.Fatalf("ParseCollection() error = %v", err)
+	}
+	if byID == nil || !byID.Map || !byID.PtrElem || byID.Elem != "User" {
+		t.Fatalf("unexpected UsersByID collection: %#v", byID)
+	}
+
+	user, err := p.ParseCollection(pkgPath, "User")
+	if err != nil || user != nil {
+		t.Fatalf("ParseCollection(User) = %#v, %v; want nil, nil", user, err)
+	}
+}
+
+func TestParse_ProtobufOneofVariants(t *testing.T) {
+	p := NewWithOptions(Options{Protobuf: true})
+
+	info, err := p.Parse("github.com/seitarof/gen-dto/testdata/protobuf/eventpb", "Event")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(info.Oneofs) != 2 {
+		t.Fatalf("expected 2 oneof variants, got %#v", info.Oneofs)
+	}
+
+	text := fieldByName(info.Oneofs, "Text")
+	if text == nil {
+		t.Fatal("Text variant not found")
+	}
+	if text.Access != FieldAccessOneof || text.Oneof == nil {
+		t.Fatalf("Text should be a oneof variant, got %#v", text)
+	}
+	if text.Oneof.Field != "Payload" || text.Oneof.WrapperStr != "Event_Text" || text.Oneof.Member != "Text" {
+		t.Fatalf("unexpected Text variant: %#v", text.Oneof)
+	}
+	if text.TypeStr != "string" {
+		t.Fatalf("Text type = %q, want string", text.TypeStr)
+	}
+
+	if info, err := New().Parse("github.com/seitarof/gen-dto/testdata/protobuf/eventpb", "Event"); err != nil || len(info.Oneofs) != 0 {
+		t.Fatalf("oneof variants should require protobuf mode, got %#v, %v", info, err)
+	}
+}
+
 func TestParse_FieldWithTypeAlias(t *testing.T) {
 	p := New()
 
@@ -138,6 +291,31 @@ func TestParse_FieldWithTypeAlias(t *testing.T) {
 	}
 }
 
+func TestParseFuncs_ExcludesOutputFile(t *testing.T) {
+	p := New()
+	pkgPath := "github.com/seitarof/gen-dto/testdata/existingconv/model"
+
+	funcs, err := p.ParseFuncs(pkgPath, "")
+	if err != nil {
+		t.Fatalf("ParseFuncs() error = %v", err)
+	}
+	if len(funcs) != 1 || funcs[0].Name != "ConvertModelMoneyToDtoMoney" {
+		t.Fatalf("unexpected funcs: %#v", funcs)
+	}
+	if funcs[0].Signature.Params().Len() != 1 {
+		t.Fatalf("unexpected signature: %s", funcs[0].Signature)
+	}
+
+	excluded := filepath.Join("..", "..", "testdata", "existingconv", "model", "money_conv.go")
+	funcs, err = p.ParseFuncs(pkgPath, excluded)
+	if err != nil {
+		t.Fatalf("ParseFuncs() error = %v", err)
+	}
+	if len(funcs) != 0 {
+		t.Fatalf("functions in excluded file should be ignored: %#v", funcs)
+	}
+}
+
 func TestShouldRecurseNestedPackage(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -187,6 +365,22 @@ func TestShouldRecurseNestedPackage(t *testing.T) {
 	}
 }
 
+func TestImportSpec_RenamesPackagesShadowedByConverterVariables(t *testing.T) {
+	tests := []struct {
+		path, name string
+		want       string
+	}{
+		{path: "time", name: "time", want: `"time"`},
+		{path: "example.com/dst", name: "dst", want: `dstpkg "example.com/dst"`},
+		{path: "example.com/v2/src", name: "src", want: `srcpkg "example.com/v2/src"`},
+	}
+	for _, tc := range tests {
+		if got := ImportSpec(tc.path, tc.name); got != tc.want {
+			t.Fatalf("ImportSpec(%q, %q) = %s, want %s", tc.path, tc.name, got, tc.want)
+		}
+	}
+}
+
 func fieldByName(fields []FieldInfo, name string) *FieldInfo {
 	for i := range fields {
 		if fields[i].Name == name {
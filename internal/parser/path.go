@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"go/types"
+)
+
+// maxPathDepth limits how many named struct fields a path may cross.
+const maxPathDepth = 3
+
+// collectPathFields returns the fields of named nested structs reachable from
+// fields, e.g. Address.City for an Address field, up to maxPathDepth levels.
+func collectPathFields(fields []FieldInfo, opts flattenOptions) []FieldInfo {
+	var out []FieldInfo
+	opts.preferred = nil
+	collectPathFieldsRec(fields, opts, 1, map[types.Type]bool{}, &out)
+	return out
+}
+
+func collectPathFieldsRec(
+	fields []FieldInfo,
+	opts flattenOptions,
+	depth int,
+	visiting map[types.Type]bool,
+	out *[]FieldInfo,
+) {
+	if depth > maxPathDepth {
+		return
+	}
+	for _, parent := range fields {
+		st, structType, isPtr := nestedStruct(parent.Type)
+		if st == nil || visiting[structType] {
+			continue
+		}
+
+		hops := parent.PointerHops
+		if isPtr {
+			hops = appendHop(hops, PointerHop{
+				AccessPath: parent.AccessPath,
+				TypeStr:    types.TypeString(structType, opts.qualifier),
+				Type:       structType,
+			})
+		}
+
+		children, _ := flattenFields(st, opts)
+		nested := make([]FieldInfo, 0, len(children))
+		for _, child := range children {
+			field := child
+			field.Name = parent.Name + child.Name
+			field.AccessPath = parent.AccessPath + "." + child.AccessPath
+			field.PointerHops = hops
+			if !parent.IsExported {
+				field.IsExported = false
+				field.AccessPkg = parent.AccessPkg
+			}
+			for _, hop := range child.PointerHops {
+				hop.AccessPath = parent.AccessPath + "." + hop.AccessPath
+				field.PointerHops = appendHop(field.PointerHops, hop)
+			}
+			nested = append(nested, field)
+		}
+		*out = append(*out, nested...)
+
+		visiting[structType] = true
+		collectPathFieldsRec(nested, opts, depth+1, visiting, out)
+		delete(visiting, structType)
+	}
+}
+
+// nestedStruct unwraps a named struct or pointer to named struct.
+func nestedStruct(t types.Type) (*types.Struct, types.Type, bool) {
+	isPtr := false
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+		isPtr = true
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return nil, nil, false
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil, false
+	}
+	return st, named, isPtr
+}
+
+func appendHop(hops []PointerHop, hop PointerHop) []PointerHop {
+	next := make([]PointerHop, 0, len(hops)+1)
+	next = append(next, hops...)
+	return append(next, hop)
+}
@@ -7,7 +7,36 @@ type StructInfo struct {
 	Name    string
 	PkgPath string
 	PkgName string
-	Fields  []FieldInfo
+	// Pkg is the type-checked package declaring the struct, in which
+	// expressions such as tag defaults are evaluated.
+	Pkg    *types.Package
+	Fields []FieldInfo
+	// PathFields lists fields of named nested structs, e.g. Address.City,
+	// named by concatenating the path (AddressCity).
+	PathFields []FieldInfo
+	// Embedded lists directly embedded structs as whole fields, e.g.
+	// Timestamps for an embedded Timestamps or *Timestamps.
+	Embedded []FieldInfo
+	// Ambiguous lists promoted field names dropped because several embedded
+	// structs provide them at the same depth.
+	Ambiguous []AmbiguousField
+	// Type and TypeArgs are set for instantiated generic structs, whose Name
+	// includes the type arguments, e.g. Page[User].
+	Type     types.Type
+	TypeArgs []TypeDetail
+	// Getters and Setters list GetX() T and SetX(T) methods as virtual
+	// fields named X.
+	Getters []FieldInfo
+	Setters []FieldInfo
+	// Oneofs lists the members of protobuf oneof variants as virtual fields,
+	// e.g. Text for a Payload oneof with an Event_Text{Text string} variant.
+	Oneofs []FieldInfo
+}
+
+// AmbiguousField is a promoted field name reachable through several paths.
+type AmbiguousField struct {
+	Name        string
+	AccessPaths []string
 }
 
 // FieldInfo stores one field mapping candidate.
@@ -18,7 +47,55 @@ type FieldInfo struct {
 	TypeInfo   TypeDetail
 	Type       types.Type
 	IsExported bool
-	EmbedFrom  string
+	// AccessPkg is the package path that declares an unexported part of
+	// AccessPath; only code in that package can access the field.
+	AccessPkg string
+	EmbedFrom string
+	// PointerHops lists nil-able pointers along AccessPath, outermost first.
+	PointerHops []PointerHop
+	// Access tells how the field is read or written.
+	Access FieldAccess
+	// Oneof describes the variant of a FieldAccessOneof field.
+	Oneof *OneofVariant
+	// Default is the expression of a dto:"default=..." tag.
+	Default string
+	// AfterAssign, set on values the resolver synthesizes for pointer
+	// destinations, holds the statements following every assignment of the
+	// value, which point the destination at it.
+	AfterAssign string
+}
+
+// OneofVariant is one protobuf oneof case: the interface field Field holds a
+// *Wrapper whose only field is Member.
+type OneofVariant struct {
+	Field string
+	// WrapperStr and Wrapper describe the variant struct, e.g. Event_Text.
+	WrapperStr string
+	Wrapper    types.Type
+	Member     string
+}
+
+// FieldAccess tells how a field is read or written.
+type FieldAccess int
+
+const (
+	// FieldAccessField selects a struct field.
+	FieldAccessField FieldAccess = iota
+	// FieldAccessGetter calls a GetX() method; AccessPath holds the call.
+	FieldAccessGetter
+	// FieldAccessSetter calls a SetX(v) method; AccessPath holds its name.
+	FieldAccessSetter
+	// FieldAccessOneof reads or writes one variant of a protobuf oneof.
+	FieldAccessOneof
+)
+
+// PointerHop is one pointer traversed on the way to a field.
+type PointerHop struct {
+	// AccessPath selects the pointer itself, e.g. Address for Address.City.
+	AccessPath string
+	// TypeStr and Type describe the pointee, used to allocate it.
+	TypeStr string
+	Type    types.Type
 }
 
 // TypeDetail keeps simplified type metadata for matching/resolution.
@@ -31,6 +108,8 @@ type TypeDetail struct {
 	BasicKind  string
 	StructName string
 	TypeName   string
+	// TypeArgs lists the type arguments of an instantiated generic type.
+	TypeArgs []TypeDetail
 }
 
 // TypeKind is coarse-grained type category.
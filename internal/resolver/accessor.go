@@ -0,0 +1,134 @@
+package resolver
+
+import (
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/matcher"
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+const (
+	// getterVar holds a getter result that must be addressable.
+	getterVar = "gv"
+	// setterVar collects the value passed to a setter.
+	setterVar = "sv"
+	// oneofVar holds the asserted variant of a oneof source.
+	oneofVar = "ov"
+)
+
+// resolveAccessors resolves pairs that read through a getter or a oneof
+// variant, or write through a setter or a oneof variant. Rules only see plain
+// selectors: a setter or variant destination is resolved into setterVar and
+// then passed on, a variant source is read from oneofVar after a type
+// assertion, and a getter result that a rule may take the address of or
+// reads more than once, e.g. for a nil check, is bound to getterVar first.
+func (r *resolverImpl) resolveAccessors(pair matcher.FieldPair) ConversionPlan {
+	src, dst := pair.SrcField, pair.DstField
+	bindGetter := src.Access == parser.FieldAccessGetter && needsAddressable(src, dst)
+	setter := dst.Access == parser.FieldAccessSetter
+	srcOneof := src.Access == parser.FieldAccessOneof && src.Oneof != nil
+	dstOneof := dst.Access == parser.FieldAccessOneof && dst.Oneof != nil
+	if !bindGetter && !setter && !srcOneof && !dstOneof {
+		plan := r.resolveOne(pair)
+		if !callsGetterTwice(plan, src) {
+			return plan
+		}
+		bindGetter = true
+	}
+
+	inner := pair
+	if bindGetter {
+		inner.SrcField.AccessPath = exprPrefix + getterVar
+	}
+	if srcOneof {
+		inner.SrcField.AccessPath = exprPrefix + oneofVar + "." + src.Oneof.Member
+	}
+	// A variant is only selected for a present source, so a pointer source is
+	// dereferenced unless the variant member is a pointer itself.
+	derefSrc := dstOneof && !srcOneof && src.Access == parser.FieldAccessField &&
+		dst.TypeInfo.Kind != parser.TypeKindPointer
+	if derefSrc {
+		if detail, elem, ok := pointerElemField(src); ok {
+			inner.SrcField.AccessPath = exprPrefix + "(*" + srcSelector(src) + ")"
+			inner.SrcField.TypeStr = strings.TrimPrefix(src.TypeStr, "*")
+			inner.SrcField.TypeInfo = detail
+			inner.SrcField.Type = elem
+			inner.SrcField.PointerHops = nil
+		}
+	}
+	if setter || dstOneof {
+		inner.DstField.AccessPath = exprPrefix + setterVar
+	}
+	plan := r.resolveOne(inner)
+	if !bindGetter && callsGetterTwice(plan, src) {
+		bindGetter = true
+		inner.SrcField.AccessPath = exprPrefix + getterVar
+		plan = r.resolveOne(inner)
+	}
+	plan.SrcField, plan.DstField = src, dst
+	if plan.Strategy == StrategySkip {
+		return plan
+	}
+
+	body := plan.Expression
+	block := bindGetter
+	if setter || dstOneof {
+		pass := func(value string) string { return "dst." + dst.AccessPath + "(" + value + ")" }
+		if dstOneof {
+			pass = func(value string) string {
+				return "dst." + dst.Oneof.Field + " = &" + dst.Oneof.WrapperStr + "{" + dst.Oneof.Member + ": " + value + "}"
+			}
+		}
+		if value, ok := strings.CutPrefix(body, setterVar+" = "); ok && !strings.Contains(value, "\n") {
+			if value == "(*"+srcSelector(src)+")" {
+				value = "*" + srcSelector(src)
+			}
+			body = pass(value)
+		} else {
+			block = true
+			body = "var " + setterVar + " " + dst.TypeStr + "\n" + body + "\n" + pass(setterVar)
+		}
+	}
+	if dstOneof && isNilable(src) && !srcOneof {
+		// Only a present source selects the variant.
+		body = "if " + srcSelector(src) + " != nil {\n" + body + "\n}"
+		block = false
+	}
+	if srcOneof {
+		body = "if " + oneofVar + ", ok := src." + src.Oneof.Field + ".(*" + src.Oneof.WrapperStr + "); ok {\n" + body + "\n}"
+		block = false
+	}
+	if bindGetter {
+		body = getterVar + " := src." + src.AccessPath + "\n" + body
+		block = true
+	}
+	if block {
+		body = "{\n" + body + "\n}"
+	}
+	plan.Expression = body
+	return plan
+}
+
+// callsGetterTwice reports whether plan calls the getter src more than once.
+func callsGetterTwice(plan ConversionPlan, src parser.FieldInfo) bool {
+	return src.Access == parser.FieldAccessGetter && strings.Count(plan.Expression, srcSelector(src)) > 1
+}
+
+// isNilable reports whether f can be nil, which leaves a oneof or a pointer
+// destination unset.
+func isNilable(f parser.FieldInfo) bool {
+	switch f.TypeInfo.Kind {
+	case parser.TypeKindPointer, parser.TypeKindSlice, parser.TypeKindMap, parser.TypeKindInterface:
+		return true
+	}
+	return false
+}
+
+// needsAddressable reports whether rules may take the address of the source,
+// which a method call result does not allow.
+func needsAddressable(src, dst parser.FieldInfo) bool {
+	if src.TypeInfo.Kind == parser.TypeKindPointer {
+		return false
+	}
+	return src.TypeInfo.Kind == parser.TypeKindStruct || dst.TypeInfo.Kind == parser.TypeKindPointer
+}
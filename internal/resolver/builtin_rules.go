@@ -2,44 +2,91 @@ package resolver
 
 import (
 	"go/types"
+	"strconv"
 	"strings"
 
 	"github.com/seitarof/gen-dto/internal/parser"
 )
 
+// Options tunes the behavior of built-in rules.
+type Options struct {
+	NullString NullStringPolicy
+	// DeepCopy clones slices, maps and pointers instead of sharing them.
+	DeepCopy  bool
+	Narrowing Narrowing
+	// Wrappers declares user-defined optional value types.
+	Wrappers []Wrapper
+	// Protobuf converts well-known protobuf messages to their Go values.
+	Protobuf bool
+	// Polymorphic declares the variants of interface-typed fields.
+	Polymorphic []Polymorphic
+	// VariantFallback handles variants without a declared pair.
+	VariantFallback VariantFallback
+	// Shape selects the signature of generated converters.
+	Shape FuncShape
+	// Params are extra parameters of every generated converter.
+	Params []Param
+}
+
+// ReturnsError reports whether generated converters return an error.
+func (o Options) ReturnsError() bool {
+	return o.Narrowing.ReturnsError() || (len(o.Polymorphic) > 0 && o.VariantFallback == VariantFallbackError)
+}
+
 // DefaultRules returns built-in rules in priority order.
 func DefaultRules() []Rule {
+	return RulesWithOptions(Options{})
+}
+
+// RulesWithOptions returns built-in rules in priority order configured by opts.
+func RulesWithOptions(opts Options) []Rule {
 	return []Rule{
-		&SameTypeRule{},
-		&BasicCastRule{},
-		&PointerRule{},
-		&NullableRule{},
+		&SameTypeRule{DeepCopy: opts.DeepCopy},
+		&PolymorphicRule{Fields: opts.Polymorphic, Fallback: opts.VariantFallback, ReturnsError: opts.ReturnsError(), Shape: opts.Shape, Params: opts.Params},
+		&BasicCastRule{Narrowing: opts.Narrowing},
+		&PointerRule{DeepCopy: opts.DeepCopy, Narrowing: opts.Narrowing},
+		&NullableRule{StringPolicy: opts.NullString},
+		&WrapperRule{Wrappers: opts.Wrappers},
+		&ProtobufRule{Enabled: opts.Protobuf},
 		&TimeStringRule{},
-		&NestedStructRule{},
-		&SliceConvertRule{},
+		&NestedStructRule{ReturnsError: opts.ReturnsError(), Shape: opts.Shape, Params: opts.Params},
+		&SliceConvertRule{DeepCopy: opts.DeepCopy, Narrowing: opts.Narrowing},
 		&StringerRule{},
-		&AssignableRule{},
+		&TextRule{ReturnsError: opts.ReturnsError()},
+		&AssignableRule{DeepCopy: opts.DeepCopy},
 		&ConvertibleRule{},
+		&ContainerRule{},
 	}
 }
 
-// SameTypeRule: identical type -> direct assignment.
-type SameTypeRule struct{}
+// SameTypeRule: identical type -> direct assignment, or a deep copy of
+// reference types when DeepCopy is set.
+type SameTypeRule struct {
+	DeepCopy bool
+}
 
 func (r *SameTypeRule) Name() string { return "same-type" }
 
 func (r *SameTypeRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	if isIdenticalType(src.Type, dst.Type) {
-		return newPlan(src, dst, StrategyDirectAssign, assign(dstSelector(dst), srcSelector(src))), true
+		return assignOrDeepCopy(src, dst, r.DeepCopy), true
 	}
 	return ConversionPlan{}, false
 }
 
-// BasicCastRule: basic/alias basic conversion with cast.
-type BasicCastRule struct{}
+// BasicCastRule: basic/alias basic conversion with cast. Lossy numeric
+// casts follow the Narrowing policy.
+type BasicCastRule struct {
+	errorReturn string
+	Narrowing   Narrowing
+}
 
 func (r *BasicCastRule) Name() string { return "basic-cast" }
 
+func (r *BasicCastRule) SetErrorReturn(prefix string) {
+	r.errorReturn = prefix
+}
+
 func (r *BasicCastRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	if !src.TypeInfo.IsBasic || !dst.TypeInfo.IsBasic {
 		return ConversionPlan{}, false
@@ -50,15 +97,32 @@ func (r *BasicCastRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	if !types.ConvertibleTo(src.Type, dst.Type) {
 		return ConversionPlan{}, false
 	}
-	expr := castAssign(dstSelector(dst), dst.TypeStr, srcSelector(src))
-	return newPlan(src, dst, StrategyBasicCast, expr), true
+	expr, lossy := r.Narrowing.castStmt(dst.Name, dstTarget(dst), dst.TypeStr, srcSelector(src), src.Type, dst.Type, r.errorReturn)
+	plan := newPlan(src, dst, StrategyBasicCast, expr)
+	plan.Lossy = lossy
+	return plan, true
 }
 
-// PointerRule: pointer <-> value conversion for non-nested types.
-type PointerRule struct{}
+// PointerRule: pointer <-> value conversion for non-nested types. With
+// DeepCopy, or for container elements, the wrapped pointer targets a copy
+// instead of the source value.
+type PointerRule struct {
+	elems       ElemResolver
+	errorReturn string
+	DeepCopy    bool
+	Narrowing   Narrowing
+}
 
 func (r *PointerRule) Name() string { return "pointer" }
 
+func (r *PointerRule) SetElemResolver(elems ElemResolver) {
+	r.elems = elems
+}
+
+func (r *PointerRule) SetErrorReturn(prefix string) {
+	r.errorReturn = prefix
+}
+
 func (r *PointerRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	srcElem, srcPtr := pointerElem(src.Type)
 	dstElem, dstPtr := pointerElem(dst.Type)
@@ -66,8 +130,7 @@ func (r *PointerRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	if srcPtr && !dstPtr {
 		if isIdenticalType(srcElem, dst.Type) {
 			srcSel := srcSelector(src)
-			dstSel := dstSelector(dst)
-			expr := "if " + srcSel + " != nil {\n" + dstSel + " = *" + srcSel + "\n}"
+			expr := "if " + srcSel + " != nil {\n" + dstTarget(dst).assign("*"+srcSel) + "\n}"
 			return newPlan(src, dst, StrategyPointerUnwrap, expr), true
 		}
 		if src.TypeInfo.ElemType != nil && src.TypeInfo.ElemType.Kind == parser.TypeKindStruct {
@@ -75,15 +138,31 @@ func (r *PointerRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 		}
 		if types.ConvertibleTo(srcElem, dst.Type) && src.TypeInfo.ElemType != nil && src.TypeInfo.ElemType.IsBasic && dst.TypeInfo.IsBasic {
 			srcSel := srcSelector(src)
-			dstSel := dstSelector(dst)
-			expr := "if " + srcSel + " != nil {\n" + dstSel + " = " + dst.TypeStr + "(*" + srcSel + ")\n}"
-			return newPlan(src, dst, StrategyPointerUnwrap, expr), true
+			dstTo := dstTarget(dst)
+			expr := "if " + srcSel + " != nil {\n" + dstTo.assign(dst.TypeStr+"(*"+srcSel+")") + "\n}"
+			stmt, lossy := r.Narrowing.castStmt(dst.Name, dstTo, dst.TypeStr, "*"+srcSel, srcElem, dst.Type, r.errorReturn)
+			if lossy {
+				expr = "if " + srcSel + " != nil {\n" + stmt + "\n}"
+			}
+			plan := newPlan(src, dst, StrategyPointerUnwrap, expr)
+			plan.Lossy = lossy
+			return plan, true
 		}
 	}
 
 	if !srcPtr && dstPtr {
 		if isIdenticalType(src.Type, dstElem) {
-			expr := assign(dstSelector(dst), "&"+srcSelector(src))
+			expr := dstTarget(dst).assign("&" + srcSelector(src))
+			// Container elements are copied, so destination elements do not
+			// point into the source slice or map.
+			inElem := r.elems != nil && r.elems.Depth() > 0
+			if r.DeepCopy || inElem {
+				body := "v := " + srcSelector(src)
+				if r.DeepCopy && needsDeepCopy(src.Type) {
+					body += "\n" + deepCopyStmts(exprTarget("v"), "v", src.Type, 0)
+				}
+				expr = "{\n" + body + "\n" + dstTarget(dst).assign("&v") + "\n}"
+			}
 			return newPlan(src, dst, StrategyPointerWrap, expr), true
 		}
 		if dst.TypeInfo.ElemType != nil && dst.TypeInfo.ElemType.Kind == parser.TypeKindStruct {
@@ -92,63 +171,110 @@ func (r *PointerRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 		if types.ConvertibleTo(src.Type, dstElem) && src.TypeInfo.IsBasic && dst.TypeInfo.ElemType != nil && dst.TypeInfo.ElemType.IsBasic {
 			dstElemType := strings.TrimPrefix(dst.TypeStr, "*")
 			srcSel := srcSelector(src)
-			dstSel := dstSelector(dst)
-			expr := "{\nv := " + dstElemType + "(" + srcSel + ")\n" + dstSel + " = &v\n}"
-			return newPlan(src, dst, StrategyPointerWrap, expr), true
+			dstTo := dstTarget(dst)
+			expr := "{\nv := " + dstElemType + "(" + srcSel + ")\n" + dstTo.assign("&v") + "\n}"
+			stmt, lossy := r.Narrowing.castStmt(dst.Name, exprTarget("v"), dstElemType, srcSel, src.Type, dstElem, r.errorReturn)
+			if lossy {
+				expr = "{\nvar v " + dstElemType + "\n" + stmt + "\n" + dstTo.assign("&v") + "\n}"
+			}
+			plan := newPlan(src, dst, StrategyPointerWrap, expr)
+			plan.Lossy = lossy
+			return plan, true
 		}
 	}
 
 	return ConversionPlan{}, false
 }
 
-// NullableRule handles database/sql.Null* <-> value conversions.
-type NullableRule struct{}
+// NullableRule handles database/sql.Null* and sql.Null[T] conversions to and
+// from values and pointers. A nil pointer maps to Valid=false and back.
+type NullableRule struct {
+	StringPolicy NullStringPolicy
+}
 
 func (r *NullableRule) Name() string { return "nullable" }
 
 func (r *NullableRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
-	srcMeta, srcIsNullable := nullableTypeMeta(src.TypeInfo)
-	dstMeta, dstIsNullable := nullableTypeMeta(dst.TypeInfo)
+	srcMeta, srcIsNullable := nullableFieldMeta(src)
+	dstMeta, dstIsNullable := nullableFieldMeta(dst)
+	srcSel := srcSelector(src)
+	dstTo := dstTarget(dst)
 
-	if srcIsNullable && typeMatchesCanonical(dst.TypeInfo, srcMeta.valueType) {
-		srcSel := srcSelector(src)
-		dstSel := dstSelector(dst)
-		expr := "if " + srcSel + ".Valid {\n" + dstSel + " = " + srcSel + "." + srcMeta.valueField + "\n}"
-		return newPlan(src, dst, StrategyNullableToValue, expr), true
+	if srcIsNullable {
+		if srcMeta.matches(dst.TypeInfo, dst.Type) {
+			expr := "if " + srcSel + ".Valid {\n" + dstTo.assign(srcSel+"."+srcMeta.valueField) + "\n}"
+			return newPlan(src, dst, StrategyNullableToValue, expr), true
+		}
+		if elemDetail, elem, ok := pointerElemField(dst); ok && srcMeta.matches(elemDetail, elem) {
+			expr := "if " + srcSel + ".Valid {\n" +
+				"v := " + srcSel + "." + srcMeta.valueField + "\n" +
+				dstTo.assign("&v") + "\n}"
+			return newPlan(src, dst, StrategyNullableToValue, expr), true
+		}
 	}
 
-	if dstIsNullable && typeMatchesCanonical(src.TypeInfo, dstMeta.valueType) {
-		srcSel := srcSelector(src)
-		validExpr := dstMeta.validExpr(srcSel)
-		expr := dstSelector(dst) + " = " + dst.TypeStr + "{" + dstMeta.valueField + ": " + srcSel + ", Valid: " + validExpr + "}"
-		return newPlan(src, dst, StrategyValueToNullable, expr), true
+	if dstIsNullable {
+		if dstMeta.matches(src.TypeInfo, src.Type) {
+			validExpr := dstMeta.validExpr(srcSel)
+			if dstMeta.valueType == "string" && r.StringPolicy == NullStringAlwaysValid {
+				validExpr = "true"
+			}
+			expr := dstTo.assign(dst.TypeStr + "{" + dstMeta.valueField + ": " + srcSel + ", Valid: " + validExpr + "}")
+			return newPlan(src, dst, StrategyValueToNullable, expr), true
+		}
+		if elemDetail, elem, ok := pointerElemField(src); ok && dstMeta.matches(elemDetail, elem) {
+			expr := "if " + srcSel + " != nil {\n" +
+				dstTo.assign(dst.TypeStr+"{"+dstMeta.valueField+": *"+srcSel+", Valid: true}") + "\n}"
+			return newPlan(src, dst, StrategyValueToNullable, expr), true
+		}
 	}
 
 	return ConversionPlan{}, false
 }
 
-// TimeStringRule handles time.Time <-> string.
+// TimeStringRule handles time.Time <-> string and *time.Time <-> string in
+// RFC 3339. A nil time becomes "" and an unparsable string leaves the time
+// unset.
 type TimeStringRule struct{}
 
 func (r *TimeStringRule) Name() string { return "time-string" }
 
 func (r *TimeStringRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
-	if isTimeType(src.TypeInfo) && isStringType(dst.TypeInfo) {
-		expr := assign(dstSelector(dst), srcSelector(src)+".Format(time.RFC3339)")
-		return newPlan(src, dst, StrategyTimeToString, expr), true
+	srcSel := srcSelector(src)
+	dstTo := dstTarget(dst)
+	if isStringType(dst.TypeInfo) {
+		if isTimeType(src.TypeInfo) {
+			expr := dstTo.assign(srcSel + ".Format(time.RFC3339)")
+			return newPlan(src, dst, StrategyTimeToString, expr), true
+		}
+		if elemDetail, _, ok := pointerElemField(src); ok && isTimeType(elemDetail) {
+			expr := "if " + srcSel + " != nil {\n" + dstTo.assign(srcSel+".Format(time.RFC3339)") + "\n}"
+			return newPlan(src, dst, StrategyTimeToString, expr), true
+		}
 	}
-	if isStringType(src.TypeInfo) && isTimeType(dst.TypeInfo) {
-		srcSel := srcSelector(src)
-		dstSel := dstSelector(dst)
-		expr := "if parsed, err := time.Parse(time.RFC3339, " + srcSel + "); err == nil {\n" + dstSel + " = parsed\n}"
-		return newPlan(src, dst, StrategyStringToTime, expr), true
+	if isStringType(src.TypeInfo) {
+		if isTimeType(dst.TypeInfo) {
+			expr := "if parsed, err := time.Parse(time.RFC3339, " + srcSel + "); err == nil {\n" + dstTo.assign("parsed") + "\n}"
+			return newPlan(src, dst, StrategyStringToTime, expr), true
+		}
+		if elemDetail, _, ok := pointerElemField(dst); ok && isTimeType(elemDetail) {
+			expr := "if parsed, err := time.Parse(time.RFC3339, " + srcSel + "); err == nil {\n" + dstTo.assign("&parsed") + "\n}"
+			return newPlan(src, dst, StrategyStringToTime, expr), true
+		}
 	}
 	return ConversionPlan{}, false
 }
 
 // NestedStructRule maps nested struct fields via generated converters.
+// ReturnsError makes the emitted calls expect an error result, and Shape
+// selects how the converters are called.
 type NestedStructRule struct {
-	nestedSet NestedSet
+	nestedSet     NestedSet
+	outputPkgPath string
+	errorReturn   string
+	ReturnsError  bool
+	Shape         FuncShape
+	Params        []Param
 }
 
 func (r *NestedStructRule) Name() string { return "nested-struct" }
@@ -157,6 +283,14 @@ func (r *NestedStructRule) SetNestedSet(nestedSet NestedSet) {
 	r.nestedSet = nestedSet
 }
 
+func (r *NestedStructRule) SetOutputPackage(pkgPath string) {
+	r.outputPkgPath = pkgPath
+}
+
+func (r *NestedStructRule) SetErrorReturn(prefix string) {
+	r.errorReturn = prefix
+}
+
 func (r *NestedStructRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	if len(r.nestedSet) == 0 {
 		return ConversionPlan{}, false
@@ -167,10 +301,7 @@ func (r *NestedStructRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool)
 			if _, ok := r.nestedSet[pairKey(srcRef, dstRef)]; !ok {
 				return ConversionPlan{}, false
 			}
-			fn := DefaultConverterName(srcRef.pkgPath, srcRef.name, dstRef.pkgPath, dstRef.name)
-			srcSel := srcSelector(src)
-			dstSel := dstSelector(dst)
-			expr := "if v := " + fn + "(&" + srcSel + "); v != nil {\n" + dstSel + " = *v\n}"
+			expr := r.converter(srcRef, dstRef).convert(srcSelector(src), false, dstTarget(dst), dst.TypeStr, false, r.errorReturn)
 			return newPlan(src, dst, StrategyNestedStruct, expr), true
 		}
 	}
@@ -180,8 +311,7 @@ func (r *NestedStructRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool)
 			if _, ok := r.nestedSet[pairKey(srcRef, dstRef)]; !ok {
 				return ConversionPlan{}, false
 			}
-			fn := DefaultConverterName(srcRef.pkgPath, srcRef.name, dstRef.pkgPath, dstRef.name)
-			expr := assign(dstSelector(dst), fn+"("+srcSelector(src)+")")
+			expr := r.converter(srcRef, dstRef).convert(srcSelector(src), true, dstTarget(dst), strings.TrimPrefix(dst.TypeStr, "*"), true, r.errorReturn)
 			return newPlan(src, dst, StrategyNestedStructPtr, expr), true
 		}
 	}
@@ -191,10 +321,7 @@ func (r *NestedStructRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool)
 			if _, ok := r.nestedSet[pairKey(srcRef, dstRef)]; !ok {
 				return ConversionPlan{}, false
 			}
-			fn := DefaultConverterName(srcRef.pkgPath, srcRef.name, dstRef.pkgPath, dstRef.name)
-			srcSel := srcSelector(src)
-			dstSel := dstSelector(dst)
-			expr := "if v := " + fn + "(" + srcSel + "); v != nil {\n" + dstSel + " = *v\n}"
+			expr := r.converter(srcRef, dstRef).convert(srcSelector(src), true, dstTarget(dst), dst.TypeStr, false, r.errorReturn)
 			return newPlan(src, dst, StrategyNestedStructPtr, expr), true
 		}
 	}
@@ -204,25 +331,25 @@ func (r *NestedStructRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool)
 			if _, ok := r.nestedSet[pairKey(srcRef, dstRef)]; !ok {
 				return ConversionPlan{}, false
 			}
-			fn := DefaultConverterName(srcRef.pkgPath, srcRef.name, dstRef.pkgPath, dstRef.name)
-			expr := assign(dstSelector(dst), fn+"(&"+srcSelector(src)+")")
+			expr := r.converter(srcRef, dstRef).convert(srcSelector(src), false, dstTarget(dst), strings.TrimPrefix(dst.TypeStr, "*"), true, r.errorReturn)
 			return newPlan(src, dst, StrategyNestedStructPtr, expr), true
 		}
 	}
 
-	if srcRef, ok := sliceStructRef(src.TypeInfo); ok {
-		if dstRef, ok := sliceStructRef(dst.TypeInfo); ok {
+	if srcRef, srcPtrElem, ok := sliceStructRef(src.TypeInfo); ok {
+		if dstRef, dstPtrElem, ok := sliceStructRef(dst.TypeInfo); ok {
 			if _, ok := r.nestedSet[pairKey(srcRef, dstRef)]; !ok {
 				return ConversionPlan{}, false
 			}
-			fn := DefaultConverterName(srcRef.pkgPath, srcRef.name, dstRef.pkgPath, dstRef.name)
 			srcSel := srcSelector(src)
-			dstSel := dstSelector(dst)
+			dstTo := dstTarget(dst)
+			// A nil source element stays nil for []*U and becomes the zero value for []U.
+			elemType := strings.TrimPrefix(strings.TrimPrefix(dst.TypeStr, "[]"), "*")
+			call := r.converter(srcRef, dstRef).convert(srcSel+"[i]", srcPtrElem, exprTarget(dstTo.sel+"[i]"), elemType, dstPtrElem, r.errorReturn)
 			expr := "if " + srcSel + " != nil {\n" +
-				dstSel + " = make(" + dst.TypeStr + ", len(" + srcSel + "))\n" +
+				dstTo.assign("make("+dst.TypeStr+", len("+srcSel+"))") + "\n" +
 				"for i := range " + srcSel + " {\n" +
-				"if v := " + fn + "(&" + srcSel + "[i]); v != nil {\n" +
-				dstSel + "[i] = *v\n}\n}\n}"
+				call + "\n}\n}"
 			return newPlan(src, dst, StrategyNestedSlice, expr), true
 		}
 	}
@@ -230,11 +357,25 @@ func (r *NestedStructRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool)
 	return ConversionPlan{}, false
 }
 
+func (r *NestedStructRule) converter(srcRef, dstRef structRef) Converter {
+	conv := ConverterFor(r.Shape, r.outputPkgPath, srcRef.pkgPath, srcRef.name, dstRef.pkgPath, dstRef.name, r.ReturnsError)
+	conv.Params = r.Params
+	return conv
+}
+
 // SliceConvertRule handles []A -> []B element casts.
-type SliceConvertRule struct{}
+type SliceConvertRule struct {
+	errorReturn string
+	DeepCopy    bool
+	Narrowing   Narrowing
+}
 
 func (r *SliceConvertRule) Name() string { return "slice-convert" }
 
+func (r *SliceConvertRule) SetErrorReturn(prefix string) {
+	r.errorReturn = prefix
+}
+
 func (r *SliceConvertRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	srcElemType, ok := sliceElem(src.Type)
 	if !ok {
@@ -256,19 +397,31 @@ func (r *SliceConvertRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool)
 
 	dstElemTypeStr := strings.TrimPrefix(dst.TypeStr, "[]")
 	srcSel := srcSelector(src)
-	dstSel := dstSelector(dst)
+	dstTo := dstTarget(dst)
+	dstSel := dstTo.sel
 	assignExpr := dstSel + "[i] = " + srcSel + "[i]"
 	strategy := StrategyDirectAssign
+	lossy := false
+	if r.DeepCopy && needsDeepCopy(srcElemType) {
+		assignExpr = deepCopyStmts(exprTarget(dstSel+"[i]"), srcSel+"[i]", srcElemType, 1)
+		strategy = StrategyDeepCopy
+	}
 	if !types.Identical(srcElemType, dstElemType) {
 		assignExpr = dstSel + "[i] = " + dstElemTypeStr + "(" + srcSel + "[i])"
 		strategy = StrategySliceConvert
+		var stmt string
+		if stmt, lossy = r.Narrowing.castStmt(dst.Name, exprTarget(dstSel+"[i]"), dstElemTypeStr, srcSel+"[i]", srcElemType, dstElemType, r.errorReturn); lossy {
+			assignExpr = stmt
+		}
 	}
 
 	expr := "if " + srcSel + " != nil {\n" +
-		dstSel + " = make(" + dst.TypeStr + ", len(" + srcSel + "))\n" +
+		dstTo.assign("make("+dst.TypeStr+", len("+srcSel+"))") + "\n" +
 		"for i := range " + srcSel + " {\n" +
 		assignExpr + "\n}\n}"
-	return newPlan(src, dst, strategy, expr), true
+	plan := newPlan(src, dst, strategy, expr)
+	plan.Lossy = lossy
+	return plan, true
 }
 
 // StringerRule calls String() for string destinations.
@@ -282,22 +435,93 @@ func (r *StringerRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	}
 	if _, ok := pointerElem(src.Type); ok {
 		srcSel := srcSelector(src)
-		dstSel := dstSelector(dst)
-		expr := "if " + srcSel + " != nil {\n" + dstSel + " = " + srcSel + ".String()\n}"
+		expr := "if " + srcSel + " != nil {\n" + dstTarget(dst).assign(srcSel+".String()") + "\n}"
 		return newPlan(src, dst, StrategyCustomFunc, expr), true
 	}
-	expr := assign(dstSelector(dst), srcSelector(src)+".String()")
+	expr := dstTarget(dst).assign(srcSelector(src) + ".String()")
 	return newPlan(src, dst, StrategyCustomFunc, expr), true
 }
 
+// TextRule converts between strings and types implementing
+// encoding.TextMarshaler/TextUnmarshaler or exposing a package-level
+// ParseX(string) (X, error) function.
+type TextRule struct {
+	elems       ElemResolver
+	errorReturn string
+	// scopes caches the packages loaded to find parse functions, nil for
+	// packages that failed to load.
+	scopes map[string]*types.Scope
+	// ReturnsError makes converters return parse errors instead of leaving
+	// the destination unset.
+	ReturnsError bool
+}
+
+func (r *TextRule) Name() string { return "text" }
+
+func (r *TextRule) SetElemResolver(elems ElemResolver) {
+	r.elems = elems
+}
+
+func (r *TextRule) SetErrorReturn(prefix string) {
+	r.errorReturn = prefix
+}
+
+func (r *TextRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
+	if _, ok := pointerElem(src.Type); ok {
+		return ConversionPlan{}, false
+	}
+	if _, ok := pointerElem(dst.Type); ok {
+		return ConversionPlan{}, false
+	}
+
+	if isStringType(dst.TypeInfo) && !isStringType(src.TypeInfo) && hasMarshalTextMethod(src.Type) {
+		srcSel := srcSelector(src)
+		expr := "if b, err := " + srcSel + ".MarshalText(); err == nil {\n" + dstTarget(dst).assign(dst.TypeStr+"(b)") + "\n}"
+		return newPlan(src, dst, StrategyTextMarshal, expr), true
+	}
+
+	if !isStringType(src.TypeInfo) || isStringType(dst.TypeInfo) {
+		return ConversionPlan{}, false
+	}
+	srcSel := srcSelector(src)
+	dstTo := dstTarget(dst)
+	if fn, ok := r.parseFuncFor(dst.Type); ok {
+		arg := srcSel
+		if src.TypeStr != "string" {
+			arg = "string(" + srcSel + ")"
+		}
+		call := r.qualifier(fn.named) + fn.name + "(" + arg + ")"
+		expr := "if v, err := " + call + "; err == nil {\n" + dstTo.assign("v") + "\n}"
+		if r.ReturnsError {
+			expr = "if v, err := " + call + "; err != nil {\n" + parseError(dst, r.errorReturn) + "\n} else {\n" + dstTo.assign("v") + "\n}"
+		}
+		return newPlan(src, dst, StrategyParseFunc, expr), true
+	}
+	if hasUnmarshalTextMethod(dst.Type) {
+		unmarshal := "v.UnmarshalText([]byte(" + srcSel + "))"
+		expr := "{\nvar v " + dst.TypeStr + "\n" +
+			"if err := " + unmarshal + "; err == nil {\n" +
+			dstTo.assign("v") + "\n}\n}"
+		if r.ReturnsError {
+			expr = "{\nvar v " + dst.TypeStr + "\n" +
+				"if err := " + unmarshal + "; err != nil {\n" + parseError(dst, r.errorReturn) + "\n}\n" +
+				dstTo.assign("v") + "\n}"
+		}
+		return newPlan(src, dst, StrategyTextUnmarshal, expr), true
+	}
+	return ConversionPlan{}, false
+}
+
 // AssignableRule uses types.AssignableTo.
-type AssignableRule struct{}
+type AssignableRule struct {
+	DeepCopy bool
+}
 
 func (r *AssignableRule) Name() string { return "assignable" }
 
 func (r *AssignableRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
 	if types.AssignableTo(src.Type, dst.Type) {
-		return newPlan(src, dst, StrategyDirectAssign, assign(dstSelector(dst), srcSelector(src))), true
+		return assignOrDeepCopy(src, dst, r.DeepCopy), true
 	}
 	return ConversionPlan{}, false
 }
@@ -312,16 +536,35 @@ func (r *ConvertibleRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool)
 		return ConversionPlan{}, false
 	}
 	if types.ConvertibleTo(src.Type, dst.Type) {
-		expr := castAssign(dstSelector(dst), dst.TypeStr, srcSelector(src))
+		expr := dstTarget(dst).cast(dst.TypeStr, srcSelector(src))
 		return newPlan(src, dst, StrategyBasicCast, expr), true
 	}
 	return ConversionPlan{}, false
 }
 
+// NullStringPolicy decides when a string value becomes a valid sql.NullString.
+type NullStringPolicy int
+
+const (
+	// NullStringEmptyIsNull stores "" as NULL (Valid=false).
+	NullStringEmptyIsNull NullStringPolicy = iota
+	// NullStringAlwaysValid stores every string, including "", as valid.
+	NullStringAlwaysValid
+)
+
 type nullableMeta struct {
 	valueType  string
 	valueField string
 	validExpr  func(srcExpr string) string
+	// typeArg is set for generic sql.Null[T] and replaces valueType.
+	typeArg types.Type
+}
+
+func (m nullableMeta) matches(detail parser.TypeDetail, t types.Type) bool {
+	if m.typeArg != nil {
+		return isIdenticalType(m.typeArg, t)
+	}
+	return typeMatchesCanonical(detail, m.valueType)
 }
 
 var nullableMapping = map[string]nullableMeta{
@@ -380,12 +623,48 @@ func newPlan(src, dst parser.FieldInfo, strategy ConversionStrategy, expression
 	}
 }
 
+func assignOrDeepCopy(src, dst parser.FieldInfo, deepCopy bool) ConversionPlan {
+	if deepCopy && needsDeepCopy(src.Type) {
+		expr := deepCopyStmts(dstTarget(dst), srcSelector(src), src.Type, 0)
+		return newPlan(src, dst, StrategyDeepCopy, expr)
+	}
+	return newPlan(src, dst, StrategyDirectAssign, dstTarget(dst).assign(srcSelector(src)))
+}
+
 func assign(dst, src string) string {
 	return dst + " = " + src
 }
 
-func castAssign(dst, dstType, src string) string {
-	return dst + " = (" + dstType + ")(" + src + ")"
+// target is where a plan stores its value.
+type target struct {
+	// sel is the assignable expression holding the value.
+	sel string
+	// then follows every assignment of sel, e.g. to point a pointer
+	// destination at it only once its value is converted.
+	then string
+}
+
+// exprTarget returns the target of the plain expression sel.
+func exprTarget(sel string) target {
+	return target{sel: sel}
+}
+
+// dstTarget returns the target of dst.
+func dstTarget(dst parser.FieldInfo) target {
+	return target{sel: dstSelector(dst), then: dst.AfterAssign}
+}
+
+// assign stores value in t.
+func (t target) assign(value string) string {
+	if t.then == "" {
+		return assign(t.sel, value)
+	}
+	return assign(t.sel, value) + "\n" + t.then
+}
+
+// cast stores value converted to dstType in t.
+func (t target) cast(dstType, value string) string {
+	return t.assign("(" + dstType + ")(" + value + ")")
 }
 
 func isConvertibleFallbackKind(kind parser.TypeKind) bool {
@@ -398,11 +677,20 @@ func isConvertibleFallbackKind(kind parser.TypeKind) bool {
 }
 
 func srcSelector(f parser.FieldInfo) string {
-	return "src." + f.AccessPath
+	return selector("src", f)
 }
 
 func dstSelector(f parser.FieldInfo) string {
-	return "dst." + f.AccessPath
+	return selector("dst", f)
+}
+
+// selector joins root and the field access path. Element fields synthesized
+// by ContainerRule carry a complete expression marked with exprPrefix instead.
+func selector(root string, f parser.FieldInfo) string {
+	if expr, ok := strings.CutPrefix(f.AccessPath, exprPrefix); ok {
+		return expr
+	}
+	return root + "." + f.AccessPath
 }
 
 func isIdenticalType(src, dst types.Type) bool {
@@ -451,6 +739,40 @@ func sliceElem(t types.Type) (types.Type, bool) {
 	return nil, false
 }
 
+func nullableFieldMeta(f parser.FieldInfo) (nullableMeta, bool) {
+	if typeArg, ok := genericNullArg(f.Type); ok {
+		return nullableMeta{
+			valueField: "V",
+			validExpr:  func(string) string { return "true" },
+			typeArg:    typeArg,
+		}, true
+	}
+	return nullableTypeMeta(f.TypeInfo)
+}
+
+// genericNullArg returns T for a database/sql.Null[T] instantiation.
+func genericNullArg(t types.Type) (types.Type, bool) {
+	if t == nil {
+		return nil, false
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.TypeArgs().Len() != 1 {
+		return nil, false
+	}
+	if named.Obj().Pkg().Path() != "database/sql" || named.Obj().Name() != "Null" {
+		return nil, false
+	}
+	return named.TypeArgs().At(0), true
+}
+
+func pointerElemField(f parser.FieldInfo) (parser.TypeDetail, types.Type, bool) {
+	if f.TypeInfo.Kind != parser.TypeKindPointer || f.TypeInfo.ElemType == nil {
+		return parser.TypeDetail{}, nil, false
+	}
+	elem, _ := pointerElem(f.Type)
+	return *f.TypeInfo.ElemType, elem, true
+}
+
 func nullableTypeMeta(detail parser.TypeDetail) (nullableMeta, bool) {
 	if detail.Kind != parser.TypeKindStruct || detail.PkgPath == "" || detail.StructName == "" {
 		return nullableMeta{}, false
@@ -506,14 +828,16 @@ func ptrStructRef(detail parser.TypeDetail) (structRef, bool) {
 	return structRefFromDetail(*detail.ElemType)
 }
 
-func sliceStructRef(detail parser.TypeDetail) (structRef, bool) {
+// sliceStructRef matches []T and []*T; ptrElem reports the latter.
+func sliceStructRef(detail parser.TypeDetail) (ref structRef, ptrElem bool, ok bool) {
 	if detail.Kind != parser.TypeKindSlice || detail.ElemType == nil {
-		return structRef{}, false
+		return structRef{}, false, false
 	}
 	if ref, ok := structRefFromDetail(*detail.ElemType); ok {
-		return ref, true
+		return ref, false, true
 	}
-	return ptrStructRef(*detail.ElemType)
+	ref, ok = ptrStructRef(*detail.ElemType)
+	return ref, ok, ok
 }
 
 func hasStringMethod(t types.Type) bool {
@@ -550,3 +874,144 @@ func hasStringMethodOnType(t types.Type) bool {
 	}
 	return false
 }
+
+type parseFunc struct {
+	name  string
+	named *types.Named
+}
+
+// qualifier returns the prefix naming declarations of named's package in the
+// generated file, e.g. "netip." or "" for the output package.
+func (r *TextRule) qualifier(named *types.Named) string {
+	typeStr := types.TypeString(named, func(pkg *types.Package) string { return parser.ImportName(pkg.Name()) })
+	if r.elems != nil {
+		typeStr = r.elems.TypeString(named)
+	}
+	return strings.TrimSuffix(typeStr, named.Obj().Name())
+}
+
+// parseError returns the error of a failed parse into dst with errorReturn,
+// worded like the narrowing checks.
+func parseError(dst parser.FieldInfo, errorReturn string) string {
+	return errorReturn + "fmt.Errorf(" + strconv.Quote("gen-dto: "+dst.Name+": %w") + ", err)"
+}
+
+// parseFuncFor finds ParseX(string) (X, error), or Parse(string) (X, error),
+// declared next to named type X. Packages known only through export data
+// expose just the objects their importers reference, so a miss loads the
+// declaring package itself.
+func (r *TextRule) parseFuncFor(t types.Type) (parseFunc, bool) {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.TypeArgs().Len() > 0 {
+		return parseFunc{}, false
+	}
+	if fn, ok := lookupParseFunc(named.Obj().Pkg().Scope(), named); ok {
+		return fn, true
+	}
+	if scope := r.packageScope(named.Obj().Pkg().Path()); scope != nil {
+		return lookupParseFunc(scope, named)
+	}
+	return parseFunc{}, false
+}
+
+func (r *TextRule) packageScope(pkgPath string) *types.Scope {
+	if scope, ok := r.scopes[pkgPath]; ok {
+		return scope
+	}
+	if r.scopes == nil {
+		r.scopes = map[string]*types.Scope{}
+	}
+	scope, _ := parser.PackageScope(pkgPath)
+	r.scopes[pkgPath] = scope
+	return scope
+}
+
+// lookupParseFunc looks up the parse function of named in scope, which may
+// belong to a separate load of named's package, so types are compared by
+// name.
+func lookupParseFunc(scope *types.Scope, named *types.Named) (parseFunc, bool) {
+	typeName := named.Obj().Name()
+	for _, name := range []string{"Parse" + typeName, "Parse"} {
+		fn, ok := scope.Lookup(name).(*types.Func)
+		if !ok || !fn.Exported() {
+			continue
+		}
+		sig, ok := fn.Type().(*types.Signature)
+		if !ok || sig.Params().Len() != 1 || sig.Results().Len() != 2 {
+			continue
+		}
+		if !isBasicKind(sig.Params().At(0).Type(), types.String) {
+			continue
+		}
+		if !sameNamed(sig.Results().At(0).Type(), named) || !isErrorType(sig.Results().At(1).Type()) {
+			continue
+		}
+		return parseFunc{name: name, named: named}, true
+	}
+	return parseFunc{}, false
+}
+
+// sameNamed reports whether t is the named type named, possibly loaded
+// separately.
+func sameNamed(t types.Type, named *types.Named) bool {
+	n, ok := types.Unalias(t).(*types.Named)
+	if !ok || n.Obj().Pkg() == nil || n.TypeArgs().Len() > 0 {
+		return false
+	}
+	return n.Obj().Name() == named.Obj().Name() && n.Obj().Pkg().Path() == named.Obj().Pkg().Path()
+}
+
+func hasMarshalTextMethod(t types.Type) bool {
+	return hasMethod(t, "MarshalText", func(sig *types.Signature) bool {
+		if sig.Params().Len() != 0 || sig.Results().Len() != 2 {
+			return false
+		}
+		return isByteSlice(sig.Results().At(0).Type()) && isErrorType(sig.Results().At(1).Type())
+	})
+}
+
+func hasUnmarshalTextMethod(t types.Type) bool {
+	return hasMethod(t, "UnmarshalText", func(sig *types.Signature) bool {
+		if sig.Params().Len() != 1 || sig.Results().Len() != 1 {
+			return false
+		}
+		return isByteSlice(sig.Params().At(0).Type()) && isErrorType(sig.Results().At(0).Type())
+	})
+}
+
+// hasMethod reports whether t or *t has a method with the given name whose
+// signature satisfies match.
+func hasMethod(t types.Type, name string, match func(*types.Signature) bool) bool {
+	if t == nil {
+		return false
+	}
+	if _, ok := types.Unalias(t).(*types.Pointer); !ok {
+		t = types.NewPointer(t)
+	}
+	ms := types.NewMethodSet(t)
+	for i := 0; i < ms.Len(); i++ {
+		sel := ms.At(i)
+		if sel.Obj().Name() != name {
+			continue
+		}
+		sig, ok := sel.Obj().Type().(*types.Signature)
+		if ok && match(sig) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBasicKind(t types.Type, kind types.BasicKind) bool {
+	b, ok := types.Unalias(t).(*types.Basic)
+	return ok && b.Kind() == kind
+}
+
+func isByteSlice(t types.Type) bool {
+	s, ok := types.Unalias(t).(*types.Slice)
+	return ok && isBasicKind(s.Elem(), types.Byte)
+}
+
+func isErrorType(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
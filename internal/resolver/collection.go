@@ -0,0 +1,101 @@
+package resolver
+
+import (
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// CollectionKind selects a collection converter generated for a struct pair.
+type CollectionKind int
+
+const (
+	// CollectionSlice converts []Src to []Dst.
+	CollectionSlice CollectionKind = iota
+	// CollectionPointerSlice converts []*Src to []*Dst.
+	CollectionPointerSlice
+	// CollectionMap converts map[K]Src to map[K]Dst for any comparable K.
+	CollectionMap
+)
+
+// CollectionPlan describes a converter of a slice or map whose elements are
+// converted by the struct converter of the enclosing StructConversionPlan.
+type CollectionPlan struct {
+	FuncName string
+	Map      bool
+	// SrcPtrElem and DstPtrElem report elements held as pointers.
+	SrcPtrElem bool
+	DstPtrElem bool
+	// SrcNamed and DstNamed are set for named collections such as Users;
+	// other collections are spelled out from their element types.
+	SrcNamed *parser.CollectionInfo
+	DstNamed *parser.CollectionInfo
+	// Elem converts one element.
+	Elem Converter
+}
+
+// NewCollectionPlan returns the collection converter of kind for the struct
+// pair converted by elem.
+func NewCollectionPlan(kind CollectionKind, srcPkgPath, srcName, dstPkgPath, dstName string, elem Converter) CollectionPlan {
+	p := CollectionPlan{Elem: elem}
+	var suffix func(string) string
+	switch kind {
+	case CollectionPointerSlice:
+		p.SrcPtrElem, p.DstPtrElem = true, true
+		suffix = func(name string) string { return name + "Ptrs" }
+	case CollectionMap:
+		p.Map = true
+		suffix = func(name string) string { return name + "Map" }
+	default:
+		suffix = plural
+	}
+	p.FuncName = converterName(srcPkgPath, srcName, dstPkgPath, dstName, suffix)
+	return p
+}
+
+// NewNamedCollectionPlan returns the converter of the named collection src to
+// dst, whose elements are converted by elem.
+func NewNamedCollectionPlan(src, dst *parser.CollectionInfo, elem Converter) CollectionPlan {
+	return CollectionPlan{
+		FuncName:   DefaultConverterName(src.PkgPath, src.Name, dst.PkgPath, dst.Name),
+		Map:        src.Map,
+		SrcPtrElem: src.PtrElem,
+		DstPtrElem: dst.PtrElem,
+		SrcNamed:   src,
+		DstNamed:   dst,
+		Elem:       elem,
+	}
+}
+
+// Loop returns the statements filling dst from src, given the destination
+// element type dstElemType without its pointer. Collection converters return
+// the collection and an error, so they fail with "return nil, ".
+func (p CollectionPlan) Loop(dstElemType string) string {
+	const errorReturn = "return nil, "
+	if !p.Map {
+		return "for i := range src {\n" +
+			p.Elem.convert("src[i]", p.SrcPtrElem, exprTarget("dst[i]"), dstElemType, p.DstPtrElem, errorReturn) + "\n}"
+	}
+	// Map elements are not addressable, so each one is converted in d.
+	decl := "var d " + dstElemType
+	if p.DstPtrElem {
+		decl = "var d *" + dstElemType
+	}
+	return "for k, s := range src {\n" + decl + "\n" +
+		p.Elem.convert("s", p.SrcPtrElem, exprTarget("d"), dstElemType, p.DstPtrElem, errorReturn) + "\n" +
+		"dst[k] = d\n}"
+}
+
+// plural returns the English plural of a type name, e.g. Users, Addresses
+// or Categories.
+func plural(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
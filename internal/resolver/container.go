@@ -0,0 +1,191 @@
+package resolver
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// exprPrefix marks an AccessPath that holds a complete Go expression rather
+// than a path below the src/dst root.
+const exprPrefix = "$"
+
+// ContainerRule converts slices, maps and pointers whose element, key or
+// pointee types differ. Each nested value pair is handed back to the rule
+// chain, so any nesting of supported leaf conversions composes without a
+// dedicated rule.
+type ContainerRule struct {
+	elems ElemResolver
+}
+
+func (r *ContainerRule) Name() string { return "container" }
+
+func (r *ContainerRule) SetElemResolver(elems ElemResolver) {
+	r.elems = elems
+}
+
+func (r *ContainerRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
+	if r.elems == nil || src.Type == nil || dst.Type == nil {
+		return ConversionPlan{}, false
+	}
+
+	var (
+		expr string
+		elem ConversionPlan
+		ok   bool
+	)
+	switch {
+	case src.TypeInfo.Kind == parser.TypeKindSlice && dst.TypeInfo.Kind == parser.TypeKindSlice:
+		expr, elem, ok = r.convertSlice(src, dst)
+	case src.TypeInfo.Kind == parser.TypeKindMap && dst.TypeInfo.Kind == parser.TypeKindMap:
+		expr, elem, ok = r.convertMap(src, dst)
+	case src.TypeInfo.Kind == parser.TypeKindPointer || dst.TypeInfo.Kind == parser.TypeKindPointer:
+		expr, elem, ok = r.convertPointer(src, dst)
+	}
+	if !ok {
+		return ConversionPlan{}, false
+	}
+
+	plan := newPlan(src, dst, StrategyContainerConvert, expr)
+	plan.Lossy = elem.Lossy
+	return plan, true
+}
+
+func (r *ContainerRule) convertSlice(src, dst parser.FieldInfo) (string, ConversionPlan, bool) {
+	srcElemType, _ := sliceElem(src.Type)
+	dstElemType, _ := sliceElem(dst.Type)
+	if srcElemType == nil || dstElemType == nil || src.TypeInfo.ElemType == nil || dst.TypeInfo.ElemType == nil {
+		return "", ConversionPlan{}, false
+	}
+
+	idx := "idx" + strconv.Itoa(r.elems.Depth())
+	srcSel := srcSelector(src)
+	dstSel := dstSelector(dst)
+	plan, ok := r.elems.ResolveElem(
+		r.elemField(src, srcSel+"["+idx+"]", srcElemType, *src.TypeInfo.ElemType),
+		r.elemField(dst, dstSel+"["+idx+"]", dstElemType, *dst.TypeInfo.ElemType),
+	)
+	if !ok {
+		return "", ConversionPlan{}, false
+	}
+
+	expr := "if " + srcSel + " != nil {\n" +
+		dstTarget(dst).assign("make("+dst.TypeStr+", len("+srcSel+"))") + "\n" +
+		"for " + idx + " := range " + srcSel + " {\n" +
+		plan.Expression + "\n}\n}"
+	return expr, plan, true
+}
+
+func (r *ContainerRule) convertMap(src, dst parser.FieldInfo) (string, ConversionPlan, bool) {
+	srcMap, ok := src.Type.Underlying().(*types.Map)
+	if !ok {
+		return "", ConversionPlan{}, false
+	}
+	dstMap, ok := dst.Type.Underlying().(*types.Map)
+	if !ok {
+		return "", ConversionPlan{}, false
+	}
+	if src.TypeInfo.KeyType == nil || src.TypeInfo.ElemType == nil || dst.TypeInfo.KeyType == nil || dst.TypeInfo.ElemType == nil {
+		return "", ConversionPlan{}, false
+	}
+
+	depth := strconv.Itoa(r.elems.Depth())
+	key, val := "key"+depth, "val"+depth
+	dstKey, dstVal := key, "dval"+depth
+
+	keyStmts := ""
+	lossy := false
+	if !isIdenticalType(srcMap.Key(), dstMap.Key()) {
+		dstKey = "dkey" + depth
+		keyPlan, ok := r.elems.ResolveElem(
+			r.elemField(src, key, srcMap.Key(), *src.TypeInfo.KeyType),
+			r.elemField(dst, dstKey, dstMap.Key(), *dst.TypeInfo.KeyType),
+		)
+		if !ok {
+			return "", ConversionPlan{}, false
+		}
+		keyStmts = "var " + dstKey + " " + r.elems.TypeString(dstMap.Key()) + "\n" + keyPlan.Expression + "\n"
+		lossy = keyPlan.Lossy
+	}
+
+	valPlan, ok := r.elems.ResolveElem(
+		r.elemField(src, val, srcMap.Elem(), *src.TypeInfo.ElemType),
+		r.elemField(dst, dstVal, dstMap.Elem(), *dst.TypeInfo.ElemType),
+	)
+	if !ok {
+		return "", ConversionPlan{}, false
+	}
+	valPlan.Lossy = valPlan.Lossy || lossy
+
+	srcSel := srcSelector(src)
+	dstSel := dstSelector(dst)
+	expr := "if " + srcSel + " != nil {\n" +
+		dstTarget(dst).assign("make("+dst.TypeStr+", len("+srcSel+"))") + "\n" +
+		"for " + key + ", " + val + " := range " + srcSel + " {\n" +
+		keyStmts +
+		"var " + dstVal + " " + r.elems.TypeString(dstMap.Elem()) + "\n" +
+		valPlan.Expression + "\n" +
+		dstSel + "[" + dstKey + "] = " + dstVal + "\n}\n}"
+	return expr, valPlan, true
+}
+
+// convertPointer handles *A -> *B, *A -> B and A -> *B. Struct-to-struct
+// pointees are left to NestedStructRule so unrelated structs are not cast.
+func (r *ContainerRule) convertPointer(src, dst parser.FieldInfo) (string, ConversionPlan, bool) {
+	srcElem, srcIsPtr := pointerElem(src.Type)
+	dstElem, dstIsPtr := pointerElem(dst.Type)
+	local := "pval" + strconv.Itoa(r.elems.Depth())
+	srcInner, dstInner := src, dst
+	if srcIsPtr {
+		if src.TypeInfo.ElemType == nil {
+			return "", ConversionPlan{}, false
+		}
+		srcInner = r.elemField(src, "(*"+srcSelector(src)+")", srcElem, *src.TypeInfo.ElemType)
+	}
+	if dstIsPtr {
+		if dst.TypeInfo.ElemType == nil {
+			return "", ConversionPlan{}, false
+		}
+		dstInner = r.elemField(dst, local, dstElem, *dst.TypeInfo.ElemType)
+		// The destination is pointed at local once the conversion assigns
+		// it, so it stays nil when the conversion fails or skips a nil
+		// source.
+		dstInner.AfterAssign = dstTarget(dst).assign("&" + local)
+	}
+	if srcInner.TypeInfo.Kind == parser.TypeKindStruct && dstInner.TypeInfo.Kind == parser.TypeKindStruct {
+		return "", ConversionPlan{}, false
+	}
+
+	plan, ok := r.elems.ResolveElem(srcInner, dstInner)
+	if !ok {
+		return "", ConversionPlan{}, false
+	}
+
+	expr := plan.Expression
+	if dstIsPtr {
+		expr = "var " + local + " " + dstInner.TypeStr + "\n" + expr
+	}
+	srcGuard := "if " + srcSelector(src) + " != nil {\n"
+	switch {
+	case srcIsPtr || isNilable(src) && !strings.HasPrefix(plan.Expression, srcGuard):
+		// A nil source leaves the destination nil.
+		expr = srcGuard + expr + "\n}"
+	case dstIsPtr:
+		expr = "{\n" + expr + "\n}"
+	}
+	return expr, plan, true
+}
+
+// elemField synthesizes a field for a nested value reachable through expr.
+func (r *ContainerRule) elemField(parent parser.FieldInfo, expr string, t types.Type, detail parser.TypeDetail) parser.FieldInfo {
+	return parser.FieldInfo{
+		Name:       parent.Name,
+		AccessPath: exprPrefix + expr,
+		TypeStr:    r.elems.TypeString(t),
+		TypeInfo:   detail,
+		Type:       t,
+		IsExported: parent.IsExported,
+	}
+}
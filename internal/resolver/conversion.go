@@ -14,6 +14,8 @@ type ConversionPlan struct {
 	DstField   parser.FieldInfo
 	Strategy   ConversionStrategy
 	Expression string
+	// Lossy marks numeric casts that can truncate or overflow.
+	Lossy bool
 }
 
 // StructConversionPlan describes one struct converter function.
@@ -22,6 +24,35 @@ type StructConversionPlan struct {
 	Dst      *parser.StructInfo
 	FuncName string
 	Plans    []ConversionPlan
+	// Defaults are assigned before Plans run.
+	Defaults []DefaultValue
+	// BeforeHook and AfterHook are called around Plans when declared.
+	BeforeHook *Hook
+	AfterHook  *Hook
+	// ReturnsError makes the converter return an error as well.
+	ReturnsError bool
+	// Shape is the signature generated; FuncName is a method name for
+	// FuncShapeMethod.
+	Shape FuncShape
+	// Collections convert slices and maps of Src with this converter.
+	Collections []CollectionPlan
+	// Sources, when set, are merged into Dst by one converter taking each
+	// of them, in order; Src is the first and Plans, run after them, only
+	// assign computed fields.
+	Sources []MergeSource
+	// Params are declared after the converter's own parameters.
+	Params []Param
+}
+
+// Hook is a hand-written function of the output package called with the
+// source and destination of a converter, e.g.
+// afterConvertUserToUserResponse(src *User, dst *UserResponse).
+type Hook struct {
+	Name string
+	// ReturnsError makes the converter return the hook's error.
+	ReturnsError bool
+	// Params passes the converter's extra parameters after dst.
+	Params bool
 }
 
 // ConversionStrategy identifies conversion behavior.
@@ -41,17 +72,38 @@ const (
 	StrategyNestedStructPtr
 	StrategyNestedSlice
 	StrategyCustomFunc
+	StrategyTextMarshal
+	StrategyTextUnmarshal
+	StrategyParseFunc
+	StrategyDeepCopy
+	StrategyContainerConvert
 	StrategySkip
 )
 
 // DefaultConverterName returns generated converter function name.
+// Instantiated generic names such as Page[User] become PageUser.
 func DefaultConverterName(srcPkgPath, srcName, dstPkgPath, dstName string) string {
-	if srcName != dstName {
+	return converterName(srcPkgPath, srcName, dstPkgPath, dstName, func(name string) string { return name })
+}
+
+// converterName names a converter after the type names decorated by suffix,
+// prefixing the package names when the type names are equal.
+func converterName(srcPkgPath, srcName, dstPkgPath, dstName string, suffix func(string) string) string {
+	same := srcName == dstName
+	srcName, dstName = suffix(instanceToken(srcName)), suffix(instanceToken(dstName))
+	if !same {
 		return "Convert" + srcName + "To" + dstName
 	}
 	return "Convert" + packageToken(srcPkgPath) + srcName + "To" + packageToken(dstPkgPath) + dstName
 }
 
+func instanceToken(name string) string {
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	return toExportedToken(name)
+}
+
 func packageToken(pkgPath string) string {
 	base := path.Base(strings.TrimSpace(pkgPath))
 	if base == "" || base == "." || base == "/" {
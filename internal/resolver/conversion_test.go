@@ -15,3 +15,88 @@ func TestDefaultConverterName_SameTypeNameUsesPackageToken(t *testing.T) {
 		t.Fatalf("unexpected func name: %s", got)
 	}
 }
+
+func TestDefaultConverterName_GenericInstance(t *testing.T) {
+	got := DefaultConverterName("example.com/model", "Pair[string, *User]", "example.com/dto", "Pair[string, *User]")
+	if got != "ConvertModelPairStringUserToDtoPairStringUser" {
+		t.Fatalf("unexpected func name: %s", got)
+	}
+}
+
+func TestConverterFor_Method(t *testing.T) {
+	got := ConverterFor(FuncShapeMethod, "example.com/model", "example.com/model", "User", "example.com/dto", "UserResponse", false)
+	if got.Name != "ToResponse" || got.Shape != FuncShapeMethod {
+		t.Fatalf("unexpected converter: %#v", got)
+	}
+
+	got = ConverterFor(FuncShapeMethod, "example.com/model", "example.com/model", "Account", "example.com/dto", "Username", false)
+	if got.Name != "ToUsername" {
+		t.Fatalf("unexpected method name: %s", got.Name)
+	}
+
+	// Receivers outside the output package fall back to functions.
+	got = ConverterFor(FuncShapeMethod, "example.com/model", "example.com/dto", "UserResponse", "example.com/model", "User", false)
+	if got.Name != "ConvertUserResponseToUser" || got.Shape != FuncShapePointer {
+		t.Fatalf("unexpected converter: %#v", got)
+	}
+}
+
+func TestNewCollectionPlan_Names(t *testing.T) {
+	tests := []struct {
+		kind CollectionKind
+		src  string
+		dst  string
+		want string
+	}{
+		{CollectionSlice, "User", "UserResponse", "ConvertUsersToUserResponses"},
+		{CollectionSlice, "Category", "CategoryDTO", "ConvertCategoriesToCategoryDTOs"},
+		{CollectionSlice, "Address", "Address", "ConvertModelAddressesToDtoAddresses"},
+		{CollectionPointerSlice, "User", "UserResponse", "ConvertUserPtrsToUserResponsePtrs"},
+		{CollectionMap, "User", "UserResponse", "ConvertUserMapToUserResponseMap"},
+	}
+	for _, tt := range tests {
+		got := NewCollectionPlan(tt.kind, "example.com/model", tt.src, "example.com/dto", tt.dst, Converter{}).FuncName
+		if got != tt.want {
+			t.Fatalf("collection name of %s -> %s = %s, want %s", tt.src, tt.dst, got, tt.want)
+		}
+	}
+}
+
+func TestMergeNames(t *testing.T) {
+	got := MergeFuncName([]string{"User", "Profile", "Page[Stats]"}, "UserDetailResponse")
+	if got != "ConvertUserProfilePageStatsToUserDetailResponse" {
+		t.Fatalf("unexpected func name: %s", got)
+	}
+
+	params := map[string]string{
+		"User":        "user",
+		"UserStats":   "userStats",
+		"HTTPConfig":  "httpConfig",
+		"ID":          "id",
+		"Page[Stats]": "pageStats",
+		"Type":        "typeSrc",
+		"Dst":         "dstSrc",
+	}
+	for name, want := range params {
+		if got := MergeParamName(name); got != want {
+			t.Fatalf("MergeParamName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestConverter_PassesParams(t *testing.T) {
+	params := []Param{{Name: "loc", Type: "*time.Location"}, {Name: "baseURL", Type: "string"}}
+
+	conv := ConverterFor(FuncShapePointer, "example.com/model", "example.com/model", "Venue", "example.com/dto", "VenueResponse", false)
+	conv.Params = params
+	got := conv.convert("src.Venue", false, exprTarget("dst.Venue"), "dto.VenueResponse", true, "return nil, ")
+	if got != "dst.Venue = ConvertVenueToVenueResponse(&src.Venue, loc, baseURL)" {
+		t.Fatalf("unexpected call: %s", got)
+	}
+
+	conv = ConverterFor(FuncShapeMethod, "example.com/model", "example.com/model", "Venue", "example.com/dto", "VenueResponse", false)
+	conv.Params = params
+	if got := conv.call("&src.Venue"); got != "src.Venue.ToResponse(loc, baseURL)" {
+		t.Fatalf("unexpected method call: %s", got)
+	}
+}
@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// needsDeepCopy reports whether values of t share memory when assigned.
+func needsDeepCopy(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch v := t.Underlying().(type) {
+	case *types.Slice, *types.Map, *types.Pointer:
+		return true
+	case *types.Struct:
+		for i := 0; i < v.NumFields(); i++ {
+			if f := v.Field(i); f.Exported() && needsDeepCopy(f.Type()) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// deepCopyStmts returns statements that set dst to a deep copy of src.
+// Slices and maps are cloned with slices.Clone/maps.Clone, pointers get a
+// fresh copy of their target, and reference-typed elements, map values,
+// pointees and exported struct fields are copied recursively. Unexported
+// fields are shared, as are values of a recursive type below its first
+// repetition. dst and src may be the same selector, in which case the value
+// is replaced in place.
+func deepCopyStmts(dst target, src string, t types.Type, depth int) string {
+	return deepCopyValue(dst, src, t, depth, nil)
+}
+
+// deepCopyValue is deepCopyStmts inside the structs listed in copying.
+func deepCopyValue(dst target, src string, t types.Type, depth int, copying []types.Type) string {
+	switch v := t.Underlying().(type) {
+	case *types.Slice:
+		out := dst.assign("slices.Clone(" + src + ")")
+		if needsDeepCopy(v.Elem()) {
+			idx := "i" + strconv.Itoa(depth)
+			elem := dst.sel + "[" + idx + "]"
+			out += "\nfor " + idx + " := range " + dst.sel + " {\n" +
+				deepCopyValue(exprTarget(elem), elem, v.Elem(), depth+1, copying) + "\n}"
+		}
+		return out
+	case *types.Map:
+		out := dst.assign("maps.Clone(" + src + ")")
+		if needsDeepCopy(v.Elem()) {
+			key := "k" + strconv.Itoa(depth)
+			elem := dst.sel + "[" + key + "]"
+			out += "\nfor " + key + " := range " + dst.sel + " {\n" +
+				deepCopyValue(exprTarget(elem), elem, v.Elem(), depth+1, copying) + "\n}"
+		}
+		return out
+	case *types.Pointer:
+		tmp := "c" + strconv.Itoa(depth)
+		out := "if " + src + " != nil {\n" + tmp + " := *" + src + "\n"
+		if needsDeepCopy(v.Elem()) {
+			out += deepCopyValue(exprTarget(tmp), tmp, v.Elem(), depth+1, copying) + "\n"
+		}
+		return out + dst.assign("&"+tmp) + "\n}"
+	case *types.Struct:
+		var stmts []string
+		if dst.sel != src || dst.then != "" {
+			stmts = append(stmts, dst.assign(src))
+		}
+		if slices.ContainsFunc(copying, func(c types.Type) bool { return types.Identical(c, t) }) {
+			return strings.Join(stmts, "\n")
+		}
+		copying = append(copying, t)
+		for i := 0; i < v.NumFields(); i++ {
+			if f := v.Field(i); f.Exported() && needsDeepCopy(f.Type()) {
+				field := dst.sel + "." + f.Name()
+				stmts = append(stmts, deepCopyValue(exprTarget(field), field, f.Type(), depth, copying))
+			}
+		}
+		return strings.Join(stmts, "\n")
+	default:
+		return dst.assign(src)
+	}
+}
@@ -0,0 +1,133 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// defaultTypeName names the destination field type while a default is
+// type-checked.
+const defaultTypeName = "gendtoDefaultType"
+
+// DefaultValue assigns a constant to a destination field before the field
+// conversions run.
+type DefaultValue struct {
+	// AccessPath is the destination field, e.g. Kind.
+	AccessPath string
+	// Value is the constant as a Go literal, e.g. "User".
+	Value string
+}
+
+// CheckDefault evaluates expr in pkg, which must declare the destination
+// struct, and checks that it is a constant assignable to field. The value is
+// rendered as a literal so that it does not depend on the output package.
+func CheckDefault(pkg *types.Package, field parser.FieldInfo, expr string) (DefaultValue, error) {
+	if pkg == nil || field.Type == nil {
+		return DefaultValue{}, fmt.Errorf("type information unavailable for %s", field.Name)
+	}
+	if field.Access != parser.FieldAccessField || len(field.PointerHops) > 0 {
+		return DefaultValue{}, fmt.Errorf("%s must be a field reachable without pointers", field.Name)
+	}
+	if _, ok := field.Type.Underlying().(*types.Basic); !ok {
+		return DefaultValue{}, fmt.Errorf("%s has type %s, defaults need a basic underlying type", field.Name, field.TypeStr)
+	}
+
+	fset := token.NewFileSet()
+	tv, err := types.Eval(fset, pkg, token.NoPos, expr)
+	if err != nil {
+		return DefaultValue{}, evalError(err)
+	}
+	if tv.Value == nil {
+		return DefaultValue{}, fmt.Errorf("%s is not a constant", expr)
+	}
+
+	// Assign the expression to a variable of the field type to get the
+	// compiler's own assignability and overflow checks.
+	scope := types.NewPackage(pkg.Path(), pkg.Name())
+	for _, name := range pkg.Scope().Names() {
+		scope.Scope().Insert(pkg.Scope().Lookup(name))
+	}
+	scope.Scope().Insert(types.NewTypeName(token.NoPos, scope, defaultTypeName, field.Type))
+	if _, err := types.Eval(fset, scope, token.NoPos, "func() { var _ "+defaultTypeName+" = "+expr+" }"); err != nil {
+		return DefaultValue{}, evalError(err)
+	}
+	return DefaultValue{AccessPath: field.AccessPath, Value: constantLiteral(tv.Value)}, nil
+}
+
+func evalError(err error) error {
+	var typeErr types.Error
+	if errors.As(err, &typeErr) {
+		return errors.New(typeErr.Msg)
+	}
+	return err
+}
+
+func constantLiteral(v constant.Value) string {
+	if v.Kind() == constant.Float {
+		if f, ok := constant.Float64Val(v); ok {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+	return v.ExactString()
+}
+
+// GuardPresent runs plan only when its source holds a non-zero value, so that
+// a default assigned before stays in place otherwise. Sources without a
+// cheap zero check, such as structs, always override the default.
+func GuardPresent(plan ConversionPlan) ConversionPlan {
+	if plan.Strategy == StrategySkip {
+		return plan
+	}
+	src := plan.SrcField
+	if src.Access == parser.FieldAccessOneof {
+		// A oneof variant is only read when present.
+		return plan
+	}
+	cond, ok := presentCondition(src)
+	if !ok {
+		return plan
+	}
+	plan.Expression = "if " + cond + " {\n" + plan.Expression + "\n}"
+	return plan
+}
+
+func presentCondition(f parser.FieldInfo) (string, bool) {
+	if cond, ok := nilCondition(f); ok {
+		return cond, true
+	}
+	sel := srcSelector(f)
+	if isTimeType(f.TypeInfo) {
+		return "!" + sel + ".IsZero()", true
+	}
+	if f.Type == nil {
+		return "", false
+	}
+	basic, ok := f.Type.Underlying().(*types.Basic)
+	if !ok {
+		return "", false
+	}
+	switch info := basic.Info(); {
+	case info&types.IsBoolean != 0:
+		return sel, true
+	case info&types.IsString != 0:
+		return sel + ` != ""`, true
+	case info&types.IsNumeric != 0:
+		return sel + " != 0", true
+	}
+	return "", false
+}
+
+// nilCondition checks that a source field of a nillable type is set.
+func nilCondition(f parser.FieldInfo) (string, bool) {
+	switch f.TypeInfo.Kind {
+	case parser.TypeKindPointer, parser.TypeKindSlice, parser.TypeKindMap, parser.TypeKindInterface:
+		return srcSelector(f) + " != nil", true
+	}
+	return "", false
+}
@@ -0,0 +1,54 @@
+package resolver
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// MergeSource is one of several source structs merged into a destination.
+type MergeSource struct {
+	Src *parser.StructInfo
+	// Param names the converter parameter holding Src.
+	Param string
+	// Plans convert the fields taken from Src, reading it as src.
+	Plans []ConversionPlan
+}
+
+// MergeFuncName returns the name of the converter merging srcNames into
+// dstName, e.g. ConvertUserProfileStatsToUserDetailResponse.
+func MergeFuncName(srcNames []string, dstName string) string {
+	var b strings.Builder
+	b.WriteString("Convert")
+	for _, name := range srcNames {
+		b.WriteString(instanceToken(name))
+	}
+	return b.String() + "To" + instanceToken(dstName)
+}
+
+// MergeParamName returns the parameter name of a merged source, e.g. user
+// for User and pageUser for Page[User]. Names clashing with keywords or with
+// the generated code's src, dst and err get a Src suffix.
+func MergeParamName(srcName string) string {
+	runes := []rune(toExportedToken(srcName))
+	// Lower the leading run of capitals, keeping the last one of an
+	// acronym followed by a word: HTTPConfig becomes httpConfig.
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	if i > 1 && i < len(runes) {
+		i--
+	}
+	for j := 0; j < i; j++ {
+		runes[j] = unicode.ToLower(runes[j])
+	}
+	name := string(runes)
+	switch {
+	case token.IsKeyword(name), name == "src", name == "dst", name == "err":
+		return name + "Src"
+	}
+	return name
+}
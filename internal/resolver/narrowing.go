@@ -0,0 +1,214 @@
+package resolver
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// NarrowingPolicy decides how lossy numeric casts are emitted.
+type NarrowingPolicy int
+
+const (
+	// NarrowingAllow casts silently, truncating out-of-range values.
+	NarrowingAllow NarrowingPolicy = iota
+	// NarrowingWarn casts like NarrowingAllow and reports every lossy field.
+	NarrowingWarn
+	// NarrowingCheck guards lossy casts with range checks.
+	NarrowingCheck
+)
+
+// Narrowing configures lossy numeric casts.
+type Narrowing struct {
+	Policy NarrowingPolicy
+	// Clamp saturates out-of-range values in check mode instead of
+	// returning an error from the converter.
+	Clamp bool
+}
+
+// ReturnsError reports whether generated converters return an error.
+func (n Narrowing) ReturnsError() bool {
+	return n.Policy == NarrowingCheck && !n.Clamp
+}
+
+type numericRange struct {
+	min, max string
+	signed   bool
+	float    bool
+	bits     int
+}
+
+// numericRanges assumes 64-bit int and uint, as on every gc 64-bit target.
+var numericRanges = map[types.BasicKind]numericRange{
+	types.Int:     {min: "math.MinInt", max: "math.MaxInt", signed: true, bits: 64},
+	types.Int8:    {min: "math.MinInt8", max: "math.MaxInt8", signed: true, bits: 8},
+	types.Int16:   {min: "math.MinInt16", max: "math.MaxInt16", signed: true, bits: 16},
+	types.Int32:   {min: "math.MinInt32", max: "math.MaxInt32", signed: true, bits: 32},
+	types.Int64:   {min: "math.MinInt64", max: "math.MaxInt64", signed: true, bits: 64},
+	types.Uint:    {min: "0", max: "math.MaxUint", bits: 64},
+	types.Uint8:   {min: "0", max: "math.MaxUint8", bits: 8},
+	types.Uint16:  {min: "0", max: "math.MaxUint16", bits: 16},
+	types.Uint32:  {min: "0", max: "math.MaxUint32", bits: 32},
+	types.Uint64:  {min: "0", max: "math.MaxUint64", bits: 64},
+	types.Uintptr: {min: "0", max: "math.MaxUint64", bits: 64},
+	types.Float32: {min: "-math.MaxFloat32", max: "math.MaxFloat32", signed: true, float: true, bits: 32},
+	types.Float64: {min: "-math.MaxFloat64", max: "math.MaxFloat64", signed: true, float: true, bits: 64},
+}
+
+func numericRangeOf(t types.Type) (numericRange, bool) {
+	if t == nil {
+		return numericRange{}, false
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return numericRange{}, false
+	}
+	r, ok := numericRanges[b.Kind()]
+	return r, ok
+}
+
+// castBounds are the checks a value of one numeric type needs before it is
+// converted to another.
+type castBounds struct {
+	// min and max are the smallest and largest values converted exactly, or
+	// empty when the source cannot exceed them.
+	min, max string
+	// maxExclusive makes max the smallest value out of range, for float
+	// sources whose integer limits are not representable, e.g. 0x1p63.
+	maxExclusive bool
+	// nan rejects NaN, which converts to no integer.
+	nan bool
+	// roundTrip marks integer to float conversions, which round instead of
+	// overflowing. They are checked by converting the value back, since the
+	// integers a float holds exactly are not a contiguous range.
+	roundTrip bool
+}
+
+func (b castBounds) lossy() bool {
+	return b.min != "" || b.max != "" || b.nan || b.roundTrip
+}
+
+// mantissaBits are the integer bits float types hold exactly.
+var mantissaBits = map[int]int{32: 24, 64: 53}
+
+// narrowingBounds reports the bounds of dst a value of src can exceed.
+func narrowingBounds(src, dst types.Type) castBounds {
+	s, ok := numericRangeOf(src)
+	if !ok {
+		return castBounds{}
+	}
+	d, ok := numericRangeOf(dst)
+	if !ok {
+		return castBounds{}
+	}
+
+	switch {
+	case s.float && d.float:
+		if s.bits > d.bits {
+			return castBounds{min: d.min, max: d.max}
+		}
+		return castBounds{}
+	case s.float:
+		// Integer limits round up as floats: 2^63 passes x > math.MaxInt64.
+		limit := "0x1p" + strconv.Itoa(d.bits)
+		if d.signed {
+			limit = "0x1p" + strconv.Itoa(d.bits-1)
+		}
+		return castBounds{min: d.min, max: limit, maxExclusive: true, nan: true}
+	case d.float:
+		mantissa := mantissaBits[d.bits]
+		bits := s.bits
+		if s.signed {
+			bits--
+		}
+		return castBounds{roundTrip: bits > mantissa}
+	}
+
+	var b castBounds
+	if s.signed && (!d.signed || s.bits > d.bits) {
+		b.min = d.min
+	}
+	if s.signed == d.signed || s.signed {
+		if s.bits > d.bits {
+			b.max = d.max
+		}
+	} else if s.bits >= d.bits {
+		b.max = d.max
+	}
+	return b
+}
+
+// isLossyCast reports whether converting src to dst can lose data.
+func isLossyCast(src, dst types.Type) bool {
+	return narrowingBounds(src, dst).lossy()
+}
+
+// castStmt stores srcExpr converted to dstType in dst, guarded according to
+// n when the cast can overflow or, from integers to floats, round. Failed
+// checks return with errorReturn; fieldName is used in their message.
+func (n Narrowing) castStmt(fieldName string, dst target, dstType, srcExpr string, srcT, dstT types.Type, errorReturn string) (string, bool) {
+	cast := dst.cast(dstType, srcExpr)
+	bounds := narrowingBounds(srcT, dstT)
+	if !bounds.lossy() {
+		return cast, false
+	}
+	if n.Policy != NarrowingCheck {
+		return cast, true
+	}
+	if bounds.roundTrip {
+		// Rounding has no nearer value to saturate to.
+		if n.Clamp {
+			return cast, true
+		}
+		srcBasic := basicName(srcT)
+		orig := srcExpr
+		if _, ok := types.Unalias(srcT).(*types.Basic); !ok {
+			orig = srcBasic + "(" + srcExpr + ")"
+		}
+		msg := strconv.Quote("gen-dto: " + fieldName + ": value %v loses precision in " + dstType)
+		back := srcBasic + "(" + basicName(dstT) + "(" + srcExpr + "))"
+		return "if " + back + " != " + orig + " {\n" + errorReturn + "fmt.Errorf(" + msg + ", " + srcExpr + ")\n}\n" + cast, true
+	}
+
+	above := srcExpr + " > " + bounds.max
+	if bounds.maxExclusive {
+		above = srcExpr + " >= " + bounds.max
+	}
+	maxValue := bounds.max
+	if bounds.maxExclusive {
+		r, _ := numericRangeOf(dstT)
+		maxValue = r.max
+	}
+	var conds []string
+	if bounds.nan {
+		conds = append(conds, "math.IsNaN("+srcExpr+")")
+	}
+	if bounds.min != "" {
+		conds = append(conds, srcExpr+" < "+bounds.min)
+	}
+	if bounds.max != "" {
+		conds = append(conds, above)
+	}
+
+	if n.Clamp {
+		out := "switch {\n"
+		if bounds.nan {
+			out += "case math.IsNaN(" + srcExpr + "):\n" + dst.assign("0") + "\n"
+		}
+		if bounds.min != "" {
+			out += "case " + srcExpr + " < " + bounds.min + ":\n" + dst.assign(bounds.min) + "\n"
+		}
+		if bounds.max != "" {
+			out += "case " + above + ":\n" + dst.assign(maxValue) + "\n"
+		}
+		return out + "default:\n" + cast + "\n}", true
+	}
+
+	msg := strconv.Quote("gen-dto: " + fieldName + ": value %v overflows " + dstType)
+	return "if " + strings.Join(conds, " || ") + " {\n" + errorReturn + "fmt.Errorf(" + msg + ", " + srcExpr + ")\n}\n" + cast, true
+}
+
+// basicName returns the name of the basic type underlying t, e.g. int64.
+func basicName(t types.Type) string {
+	return t.Underlying().(*types.Basic).Name()
+}
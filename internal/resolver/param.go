@@ -0,0 +1,70 @@
+package resolver
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strings"
+)
+
+// Param is an extra parameter of every generated converter, e.g. loc
+// *time.Location, which converters pass on to the converters they call.
+type Param struct {
+	Name string
+	// Type is a Go type expression qualified by package names.
+	Type string
+}
+
+// Validate reports whether p can be declared by generated converters.
+func (p Param) Validate() error {
+	if !token.IsIdentifier(p.Name) {
+		return fmt.Errorf("parameter name %q is not an identifier", p.Name)
+	}
+	// Generated code declares these, and single letters, as locals.
+	switch p.Name {
+	case "src", "dst", "err", "ok":
+		return fmt.Errorf("parameter name %s is used by generated code", p.Name)
+	}
+	if len(p.Name) == 1 {
+		return fmt.Errorf("parameter name %s is too short: generated code uses single letters", p.Name)
+	}
+	expr, err := goparser.ParseExpr(p.Type)
+	if err != nil || !isTypeExpr(expr) {
+		return fmt.Errorf("parameter %s has an invalid type %q", p.Name, p.Type)
+	}
+	return nil
+}
+
+// isTypeExpr reports whether expr can only denote a type, or names one.
+func isTypeExpr(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.ArrayType, *ast.MapType, *ast.FuncType,
+		*ast.ChanType, *ast.InterfaceType, *ast.StructType, *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	case *ast.StarExpr:
+		return isTypeExpr(e.X)
+	case *ast.ParenExpr:
+		return isTypeExpr(e.X)
+	}
+	return false
+}
+
+// ParamDecls declares params as in a parameter list, e.g.
+// "loc *time.Location, base string".
+func ParamDecls(params []Param) string {
+	decls := make([]string, 0, len(params))
+	for _, p := range params {
+		decls = append(decls, p.Name+" "+p.Type)
+	}
+	return strings.Join(decls, ", ")
+}
+
+// paramNames returns the names of params, passed on as arguments.
+func paramNames(params []Param) []string {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+	return names
+}
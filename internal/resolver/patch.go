@@ -0,0 +1,29 @@
+package resolver
+
+import (
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// GuardPatch runs plan only when the patch sets its source field: when the
+// field is non-nil, or also non-zero with nonZero. Fields without a cheap
+// check, such as nested structs patched in place, are always applied.
+func GuardPatch(plan ConversionPlan, nonZero bool) ConversionPlan {
+	if plan.Strategy == StrategySkip || plan.SrcField.Access == parser.FieldAccessOneof {
+		return plan
+	}
+	cond, ok := nilCondition(plan.SrcField)
+	if !ok && nonZero {
+		cond, ok = presentCondition(plan.SrcField)
+	}
+	if !ok {
+		return plan
+	}
+	// Pointer unwraps and nested patches already check their source.
+	if strings.HasPrefix(plan.Expression, "if "+cond+" {") {
+		return plan
+	}
+	plan.Expression = "if " + cond + " {\n" + plan.Expression + "\n}"
+	return plan
+}
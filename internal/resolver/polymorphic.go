@@ -0,0 +1,223 @@
+package resolver
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// Polymorphic declares the variants of an interface-typed field. Variant
+// types are looked up in the package that declares each side's interface.
+type Polymorphic struct {
+	Field    string
+	Variants []Variant
+}
+
+// Variant pairs a source implementation with a destination implementation,
+// e.g. Card with CardDTO. It applies in both directions.
+type Variant struct {
+	Src string
+	Dst string
+}
+
+// Validate reports whether p can be used to generate conversions.
+func (p Polymorphic) Validate() error {
+	if p.Field == "" || len(p.Variants) == 0 {
+		return fmt.Errorf("polymorphic field needs a name and at least one variant")
+	}
+	for _, v := range p.Variants {
+		if v.Src == "" || v.Dst == "" {
+			return fmt.Errorf("variant of %s needs a source and a destination type", p.Field)
+		}
+	}
+	return nil
+}
+
+// VariantFallback decides what happens to a variant without a declared pair.
+type VariantFallback int
+
+const (
+	// VariantFallbackSkip leaves the destination nil.
+	VariantFallbackSkip VariantFallback = iota
+	// VariantFallbackPanic panics with the unexpected type.
+	VariantFallbackPanic
+	// VariantFallbackError makes converters return an error.
+	VariantFallbackError
+)
+
+// PolymorphicRule converts declared interface fields with a type switch that
+// calls the generated converter of each variant pair.
+type PolymorphicRule struct {
+	nestedSet     NestedSet
+	outputPkgPath string
+	errorReturn   string
+	Fields        []Polymorphic
+	Fallback      VariantFallback
+	ReturnsError  bool
+	Shape         FuncShape
+	Params        []Param
+}
+
+func (r *PolymorphicRule) Name() string { return "polymorphic" }
+
+func (r *PolymorphicRule) SetOutputPackage(pkgPath string) {
+	r.outputPkgPath = pkgPath
+}
+
+func (r *PolymorphicRule) SetErrorReturn(prefix string) {
+	r.errorReturn = prefix
+}
+
+func (r *PolymorphicRule) SetNestedSet(nestedSet NestedSet) {
+	r.nestedSet = nestedSet
+}
+
+func (r *PolymorphicRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
+	decl, ok := r.lookup(src.Name)
+	if !ok {
+		return ConversionPlan{}, false
+	}
+	srcIface, ok := namedInterface(src.Type)
+	if !ok {
+		return ConversionPlan{}, false
+	}
+	dstIface, ok := namedInterface(dst.Type)
+	if !ok {
+		return ConversionPlan{}, false
+	}
+
+	srcSel := srcSelector(src)
+	var cases strings.Builder
+	for _, v := range decl.Variants {
+		srcVariant, dstVariant, ok := variantTypes(srcIface, dstIface, v)
+		if !ok {
+			continue
+		}
+		srcRef := structRef{pkgPath: srcVariant.Obj().Pkg().Path(), name: srcVariant.Obj().Name()}
+		dstRef := structRef{pkgPath: dstVariant.Obj().Pkg().Path(), name: dstVariant.Obj().Name()}
+		if _, ok := r.nestedSet[pairKey(srcRef, dstRef)]; !ok {
+			continue
+		}
+		conv := ConverterFor(r.Shape, r.outputPkgPath, srcRef.pkgPath, srcRef.name, dstRef.pkgPath, dstRef.name, r.ReturnsError)
+		conv.Params = r.Params
+		for _, c := range variantCases(srcIface, srcVariant, typeQualifier(src.TypeStr)) {
+			call, ok := variantCall(conv, c.arg, dstTarget(dst), typeQualifier(dst.TypeStr), dstIface, dstVariant, r.errorReturn)
+			if !ok {
+				break
+			}
+			if c.arg == "v" {
+				// A typed nil pointer leaves the destination nil instead of
+				// holding a typed nil itself.
+				call = "if v != nil {\n" + call + "\n}"
+			}
+			cases.WriteString("case " + c.typ + ":\n" + call + "\n")
+		}
+	}
+	if cases.Len() == 0 {
+		return ConversionPlan{}, false
+	}
+
+	switch r.Fallback {
+	case VariantFallbackPanic:
+		cases.WriteString("case nil:\ndefault:\npanic(fmt.Sprintf(\"unsupported " + src.Name + " variant %T\", v))\n")
+	case VariantFallbackError:
+		if r.ReturnsError {
+			cases.WriteString("case nil:\ndefault:\n" + r.errorReturn + "fmt.Errorf(\"unsupported " + src.Name + " variant %T\", v)\n")
+		}
+	}
+	expr := "switch v := " + srcSel + ".(type) {\n" + cases.String() + "}"
+	return newPlan(src, dst, StrategyCustomFunc, expr), true
+}
+
+func (r *PolymorphicRule) lookup(name string) (Polymorphic, bool) {
+	for _, p := range r.Fields {
+		if strings.EqualFold(p.Field, name) {
+			return p, true
+		}
+	}
+	return Polymorphic{}, false
+}
+
+// variantCall converts v with conv and stores the result in dst, as a value
+// when the value implements the destination interface and as a pointer
+// otherwise. Errors are returned with errorReturn.
+func variantCall(conv Converter, arg string, dst target, qualifier string, iface *types.Named, variant *types.Named, errorReturn string) (string, bool) {
+	var store string
+	switch {
+	case types.Implements(variant, iface.Underlying().(*types.Interface)):
+		store = "if c != nil {\n" + dst.assign("*c") + "\n}"
+	case types.Implements(types.NewPointer(variant), iface.Underlying().(*types.Interface)):
+		store = dst.assign("c")
+	default:
+		return "", false
+	}
+	src, isValue := strings.CutPrefix(arg, "&")
+	return conv.result(src, !isValue, qualifier+variant.Obj().Name(), errorReturn) + "\n" + store, true
+}
+
+// variantCase is a type switch case matching a variant and the converter
+// argument it binds.
+type variantCase struct {
+	typ string
+	arg string
+}
+
+// variantCases returns the type switch cases for variant: T and *T when
+// value receivers implement iface, since either can be stored, and only *T
+// when pointer receivers do.
+func variantCases(iface *types.Named, variant *types.Named, qualifier string) []variantCase {
+	it := iface.Underlying().(*types.Interface)
+	name := qualifier + variant.Obj().Name()
+	pointerCase := variantCase{typ: "*" + name, arg: "v"}
+	if types.Implements(variant, it) {
+		return []variantCase{{typ: name, arg: "&v"}, pointerCase}
+	}
+	if types.Implements(types.NewPointer(variant), it) {
+		return []variantCase{pointerCase}
+	}
+	return nil
+}
+
+// variantTypes resolves v against the packages of the two interfaces, trying
+// the reverse pairing for the reverse conversion.
+func variantTypes(srcIface, dstIface *types.Named, v Variant) (*types.Named, *types.Named, bool) {
+	if s, ok := lookupNamed(srcIface, v.Src); ok {
+		if d, ok := lookupNamed(dstIface, v.Dst); ok {
+			return s, d, true
+		}
+	}
+	if s, ok := lookupNamed(srcIface, v.Dst); ok {
+		if d, ok := lookupNamed(dstIface, v.Src); ok {
+			return s, d, true
+		}
+	}
+	return nil, nil, false
+}
+
+func lookupNamed(iface *types.Named, name string) (*types.Named, bool) {
+	obj, ok := iface.Obj().Pkg().Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, false
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, false
+	}
+	_, ok = named.Underlying().(*types.Struct)
+	return named, ok
+}
+
+// namedInterface returns t when it is a named interface declared in a package.
+func namedInterface(t types.Type) (*types.Named, bool) {
+	if t == nil {
+		return nil, false
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil, false
+	}
+	_, ok = named.Underlying().(*types.Interface)
+	return named, ok
+}
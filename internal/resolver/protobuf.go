@@ -0,0 +1,118 @@
+package resolver
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// Well-known protobuf packages. Copies that keep the types/known layout, such
+// as vendored or stub packages, are recognized by their path suffix.
+const (
+	timestampPkg = "google.golang.org/protobuf/types/known/timestamppb"
+	durationPkg  = "google.golang.org/protobuf/types/known/durationpb"
+	wrappersPkg  = "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// wrapperConstructors maps wrapperspb message names to their constructors.
+var wrapperConstructors = map[string]string{
+	"DoubleValue": "Double",
+	"FloatValue":  "Float",
+	"Int64Value":  "Int64",
+	"UInt64Value": "UInt64",
+	"Int32Value":  "Int32",
+	"UInt32Value": "UInt32",
+	"BoolValue":   "Bool",
+	"StringValue": "String",
+	"BytesValue":  "Bytes",
+}
+
+// ProtobufRule converts well-known protobuf messages to their Go values:
+// *timestamppb.Timestamp <-> time.Time, *durationpb.Duration <-> time.Duration
+// and *wrapperspb.XValue <-> *T or T.
+type ProtobufRule struct {
+	Enabled bool
+}
+
+// wellKnown describes how to read and build one well-known message.
+type wellKnown struct {
+	value       types.Type
+	read        string
+	constructor string
+}
+
+func (r *ProtobufRule) Name() string { return "protobuf" }
+
+func (r *ProtobufRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
+	if !r.Enabled {
+		return ConversionPlan{}, false
+	}
+	srcSel := srcSelector(src)
+	dstTo := dstTarget(dst)
+
+	if msg, ok := lookupWellKnown(src.Type); ok {
+		if isIdenticalType(msg.value, dst.Type) {
+			expr := "if " + srcSel + " != nil {\n" + dstTo.assign(srcSel+"."+msg.read) + "\n}"
+			return newPlan(src, dst, StrategyNullableToValue, expr), true
+		}
+		if elem, ok := pointerElem(dst.Type); ok && isIdenticalType(msg.value, elem) {
+			expr := "if " + srcSel + " != nil {\n" +
+				"v := " + srcSel + "." + msg.read + "\n" +
+				dstTo.assign("&v") + "\n}"
+			return newPlan(src, dst, StrategyNullableToValue, expr), true
+		}
+		return ConversionPlan{}, false
+	}
+
+	if msg, ok := lookupWellKnown(dst.Type); ok {
+		build := typeQualifier(strings.TrimPrefix(dst.TypeStr, "*")) + msg.constructor
+		if isIdenticalType(src.Type, msg.value) {
+			expr := dstTo.assign(build + "(" + srcSel + ")")
+			return newPlan(src, dst, StrategyValueToNullable, expr), true
+		}
+		if elem, ok := pointerElem(src.Type); ok && isIdenticalType(elem, msg.value) {
+			expr := "if " + srcSel + " != nil {\n" + dstTo.assign(build+"(*"+srcSel+")") + "\n}"
+			return newPlan(src, dst, StrategyValueToNullable, expr), true
+		}
+	}
+	return ConversionPlan{}, false
+}
+
+// lookupWellKnown reports whether t is a pointer to a well-known message.
+func lookupWellKnown(t types.Type) (wellKnown, bool) {
+	if t == nil {
+		return wellKnown{}, false
+	}
+	ptr, ok := types.Unalias(t).(*types.Pointer)
+	if !ok {
+		return wellKnown{}, false
+	}
+	named, ok := types.Unalias(ptr.Elem()).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return wellKnown{}, false
+	}
+	path, name := named.Obj().Pkg().Path(), named.Obj().Name()
+
+	var msg wellKnown
+	switch {
+	case isKnownPackage(path, timestampPkg) && name == "Timestamp":
+		msg = wellKnown{read: "AsTime()", constructor: "New"}
+	case isKnownPackage(path, durationPkg) && name == "Duration":
+		msg = wellKnown{read: "AsDuration()", constructor: "New"}
+	case isKnownPackage(path, wrappersPkg) && wrapperConstructors[name] != "":
+		msg = wellKnown{read: "GetValue()", constructor: wrapperConstructors[name]}
+	default:
+		return wellKnown{}, false
+	}
+	value, ok := accessorType(ptr, msg.read)
+	if !ok {
+		return wellKnown{}, false
+	}
+	msg.value = value
+	return msg, true
+}
+
+func isKnownPackage(path, known string) bool {
+	return path == known || strings.HasSuffix(path, strings.TrimPrefix(known, "google.golang.org/protobuf"))
+}
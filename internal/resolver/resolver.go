@@ -1,6 +1,8 @@
 package resolver
 
 import (
+	"go/types"
+
 	"github.com/seitarof/gen-dto/internal/matcher"
 	"github.com/seitarof/gen-dto/internal/parser"
 )
@@ -16,14 +18,88 @@ type Rule interface {
 	Try(src, dst parser.FieldInfo) (ConversionPlan, bool)
 }
 
+// ElemResolver converts element, key and pointee values on behalf of rules
+// that handle containers.
+type ElemResolver interface {
+	// ResolveElem runs the rule chain for one nested value pair.
+	ResolveElem(src, dst parser.FieldInfo) (ConversionPlan, bool)
+	// TypeString renders t as seen from the generated file's package.
+	TypeString(t types.Type) string
+	// Depth reports how many ResolveElem calls enclose the current rule.
+	Depth() int
+}
+
+// ResolverAware can consume the resolver to convert nested values recursively.
+type ResolverAware interface {
+	SetElemResolver(ElemResolver)
+}
+
+// OutputPackageAware can consume the import path of the generated file's package.
+type OutputPackageAware interface {
+	SetOutputPackage(pkgPath string)
+}
+
+// ErrorReturnAware can consume how the converter being resolved fails: the
+// statement prefix that returns an error, e.g. "return nil, ". See
+// FuncShape.ErrorReturn.
+type ErrorReturnAware interface {
+	SetErrorReturn(prefix string)
+}
+
 type resolverImpl struct {
-	rules     []Rule
-	nestedSet NestedSet
+	rules         []Rule
+	nestedSet     NestedSet
+	outputPkgPath string
+	depth         int
 }
 
 // New builds resolver with rule chain.
 func New(rules ...Rule) Resolver {
-	return &resolverImpl{rules: rules}
+	r := &resolverImpl{rules: rules}
+	for _, rule := range rules {
+		if aware, ok := rule.(ResolverAware); ok {
+			aware.SetElemResolver(r)
+		}
+	}
+	return r
+}
+
+func (r *resolverImpl) SetOutputPackage(pkgPath string) {
+	r.outputPkgPath = pkgPath
+	for _, rule := range r.rules {
+		if aware, ok := rule.(OutputPackageAware); ok {
+			aware.SetOutputPackage(pkgPath)
+		}
+	}
+}
+
+func (r *resolverImpl) SetErrorReturn(prefix string) {
+	for _, rule := range r.rules {
+		if aware, ok := rule.(ErrorReturnAware); ok {
+			aware.SetErrorReturn(prefix)
+		}
+	}
+}
+
+func (r *resolverImpl) ResolveElem(src, dst parser.FieldInfo) (ConversionPlan, bool) {
+	r.depth++
+	defer func() { r.depth-- }()
+
+	plan := r.resolveOne(matcher.FieldPair{SrcField: src, DstField: dst})
+	return plan, plan.Strategy != StrategySkip
+}
+
+func (r *resolverImpl) TypeString(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == nil || pkg.Path() == r.outputPkgPath {
+			return ""
+		}
+		return parser.ImportName(pkg.Name())
+	})
+}
+
+func (r *resolverImpl) Depth() int {
+	return r.depth
 }
 
 func (r *resolverImpl) Resolve(
@@ -39,7 +115,7 @@ func (r *resolverImpl) Resolve(
 
 	plans := make([]ConversionPlan, 0, len(pairs))
 	for _, p := range pairs {
-		plans = append(plans, r.resolveOne(p))
+		plans = append(plans, r.resolveAccessors(p))
 	}
 	return plans
 }
@@ -60,6 +60,79 @@ func TestResolver_ValueToNullable(t *testing.T) {
 	}
 }
 
+func TestResolver_NullStringPolicy(t *testing.T) {
+	pairs := []matcher.FieldPair{{
+		SrcField: newBasicField("Name", "Name", "string", types.Typ[types.String]),
+		DstField: newNamedStructField("Name", "Name", "sql.NullString", "database/sql", "NullString"),
+	}}
+
+	plans := New(DefaultRules()...).Resolve(pairs, nil)
+	if len(plans) != 1 || !strings.Contains(plans[0].Expression, `Valid: src.Name != ""`) {
+		t.Fatalf("default policy should treat empty string as NULL: %#v", plans)
+	}
+
+	plans = New(RulesWithOptions(Options{NullString: NullStringAlwaysValid})...).Resolve(pairs, nil)
+	if len(plans) != 1 || !strings.Contains(plans[0].Expression, "Valid: true") {
+		t.Fatalf("always-valid policy should mark every string valid: %#v", plans)
+	}
+}
+
+func TestResolver_PointerNullableConversions(t *testing.T) {
+	r := New(DefaultRules()...)
+	ptr := newPointerBasicField("Name", "Name", "string", types.Typ[types.String])
+	nullable := newNamedStructField("Name", "Name", "sql.NullString", "database/sql", "NullString")
+
+	plans := r.Resolve([]matcher.FieldPair{{SrcField: ptr, DstField: nullable}}, nil)
+	if len(plans) != 1 || plans[0].Strategy != StrategyValueToNullable {
+		t.Fatalf("expected StrategyValueToNullable, got %#v", plans)
+	}
+	want := "if src.Name != nil {\ndst.Name = sql.NullString{String: *src.Name, Valid: true}\n}"
+	if plans[0].Expression != want {
+		t.Fatalf("unexpected expression: %s", plans[0].Expression)
+	}
+
+	plans = r.Resolve([]matcher.FieldPair{{SrcField: nullable, DstField: ptr}}, nil)
+	if len(plans) != 1 || plans[0].Strategy != StrategyNullableToValue {
+		t.Fatalf("expected StrategyNullableToValue, got %#v", plans)
+	}
+	want = "if src.Name.Valid {\nv := src.Name.String\ndst.Name = &v\n}"
+	if plans[0].Expression != want {
+		t.Fatalf("unexpected expression: %s", plans[0].Expression)
+	}
+}
+
+func TestResolver_GenericNullConversions(t *testing.T) {
+	r := New(DefaultRules()...)
+	nullable := newGenericNullField("Age", "Age", "sql.Null[int]", types.Typ[types.Int])
+
+	pairs := []matcher.FieldPair{{
+		SrcField: nullable,
+		DstField: newBasicField("Age", "Age", "int", types.Typ[types.Int]),
+	}}
+	plans := r.Resolve(pairs, nil)
+	if len(plans) != 1 || plans[0].Expression != "if src.Age.Valid {\ndst.Age = src.Age.V\n}" {
+		t.Fatalf("unexpected plans: %#v", plans)
+	}
+
+	pairs = []matcher.FieldPair{{
+		SrcField: newPointerBasicField("Age", "Age", "int", types.Typ[types.Int]),
+		DstField: nullable,
+	}}
+	plans = r.Resolve(pairs, nil)
+	if len(plans) != 1 || plans[0].Expression != "if src.Age != nil {\ndst.Age = sql.Null[int]{V: *src.Age, Valid: true}\n}" {
+		t.Fatalf("unexpected plans: %#v", plans)
+	}
+
+	pairs = []matcher.FieldPair{{
+		SrcField: newBasicField("Age", "Age", "int64", types.Typ[types.Int64]),
+		DstField: nullable,
+	}}
+	plans = r.Resolve(pairs, nil)
+	if len(plans) != 1 || plans[0].Strategy == StrategyValueToNullable {
+		t.Fatalf("mismatched type argument should not use nullable rule: %#v", plans)
+	}
+}
+
 func TestResolver_PointerConversions(t *testing.T) {
 	r := New(DefaultRules()...)
 
@@ -127,6 +200,50 @@ func TestResolver_SliceConvert(t *testing.T) {
 	}
 }
 
+func TestResolver_ContainerConvertsMapKeysAndValues(t *testing.T) {
+	r := New(DefaultRules()...)
+	pairs := []matcher.FieldPair{{
+		SrcField: newMapBasicField("Scores", "Scores", "map[int]int", types.Typ[types.Int], types.Typ[types.Int]),
+		DstField: newMapBasicField("Scores", "Scores", "map[int64]float64", types.Typ[types.Int64], types.Typ[types.Float64]),
+	}}
+
+	plans := r.Resolve(pairs, nil)
+	if len(plans) != 1 || plans[0].Strategy != StrategyContainerConvert {
+		t.Fatalf("expected StrategyContainerConvert, got %#v", plans)
+	}
+	want := "if src.Scores != nil {\n" +
+		"dst.Scores = make(map[int64]float64, len(src.Scores))\n" +
+		"for key0, val0 := range src.Scores {\n" +
+		"var dkey0 int64\n" +
+		"dkey0 = (int64)(key0)\n" +
+		"var dval0 float64\n" +
+		"dval0 = (float64)(val0)\n" +
+		"dst.Scores[dkey0] = dval0\n}\n}"
+	if plans[0].Expression != want {
+		t.Fatalf("unexpected expression:\n%s", plans[0].Expression)
+	}
+}
+
+func TestResolver_FailsWithErrorReturn(t *testing.T) {
+	r := New(RulesWithOptions(Options{Narrowing: Narrowing{Policy: NarrowingCheck}})...)
+	r.(ErrorReturnAware).SetErrorReturn("return ")
+	pairs := []matcher.FieldPair{{
+		SrcField: newBasicField("Count", "Count", "int64", types.Typ[types.Int64]),
+		DstField: newBasicField("Count", "Count", "int32", types.Typ[types.Int32]),
+	}}
+
+	plans := r.Resolve(pairs, nil)
+	if len(plans) != 1 || plans[0].Strategy != StrategyBasicCast {
+		t.Fatalf("expected StrategyBasicCast, got %#v", plans)
+	}
+	want := "if src.Count < math.MinInt32 || src.Count > math.MaxInt32 {\n" +
+		`return fmt.Errorf("gen-dto: Count: value %v overflows int32", src.Count)` + "\n}\n" +
+		"dst.Count = (int32)(src.Count)"
+	if plans[0].Expression != want {
+		t.Fatalf("unexpected expression:\n%s", plans[0].Expression)
+	}
+}
+
 func TestResolver_UnsupportedBecomesSkip(t *testing.T) {
 	r := New(DefaultRules()...)
 
@@ -367,3 +484,56 @@ func newSliceBasicField(name, accessPath, typeStr string, elem types.Type) parse
 		},
 	}
 }
+
+func newGenericNullField(name, accessPath, typeStr string, arg types.Type) parser.FieldInfo {
+	pkg := types.NewPackage("database/sql", "sql")
+	tparam := types.NewTypeParam(types.NewTypeName(0, pkg, "T", nil), types.NewInterfaceType(nil, nil))
+	generic := types.NewNamed(types.NewTypeName(0, pkg, "Null", nil), nil, nil)
+	generic.SetTypeParams([]*types.TypeParam{tparam})
+	generic.SetUnderlying(types.NewStruct([]*types.Var{
+		types.NewField(0, pkg, "V", tparam, false),
+		types.NewField(0, pkg, "Valid", types.Typ[types.Bool], false),
+	}, nil))
+	inst, err := types.Instantiate(nil, generic, []types.Type{arg}, true)
+	if err != nil {
+		panic(err)
+	}
+	return parser.FieldInfo{
+		Name:       name,
+		AccessPath: accessPath,
+		TypeStr:    typeStr,
+		Type:       inst,
+		TypeInfo: parser.TypeDetail{
+			Kind:       parser.TypeKindStruct,
+			PkgPath:    "database/sql",
+			StructName: "Null",
+			TypeName:   "database/sql.Null",
+		},
+	}
+}
+
+func newMapBasicField(name, accessPath, typeStr string, key, elem types.Type) parser.FieldInfo {
+	keyInfo := parser.TypeDetail{
+		Kind:      parser.TypeKindBasic,
+		IsBasic:   true,
+		BasicKind: types.TypeString(key, nil),
+		TypeName:  types.TypeString(key, nil),
+	}
+	elemInfo := parser.TypeDetail{
+		Kind:      parser.TypeKindBasic,
+		IsBasic:   true,
+		BasicKind: types.TypeString(elem, nil),
+		TypeName:  types.TypeString(elem, nil),
+	}
+	return parser.FieldInfo{
+		Name:       name,
+		AccessPath: accessPath,
+		TypeStr:    typeStr,
+		Type:       types.NewMap(key, elem),
+		TypeInfo: parser.TypeDetail{
+			Kind:     parser.TypeKindMap,
+			KeyType:  &keyInfo,
+			ElemType: &elemInfo,
+		},
+	}
+}
@@ -0,0 +1,223 @@
+package resolver
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuncShape selects the signature of generated converters.
+type FuncShape int
+
+const (
+	// FuncShapePointer generates func F(src *Src) *Dst.
+	FuncShapePointer FuncShape = iota
+	// FuncShapeValue generates func F(src Src) Dst.
+	FuncShapeValue
+	// FuncShapeMethod generates func (src *Src) ToDst() *Dst when the output
+	// package declares Src, and FuncShapePointer functions otherwise.
+	FuncShapeMethod
+	// FuncShapeInto generates func F(dst *Dst, src *Src), filling an
+	// existing destination.
+	FuncShapeInto
+	// FuncShapePatch generates func ApplyDstPatch(dst *Dst, src *Src), which
+	// only applies the fields src sets.
+	FuncShapePatch
+)
+
+// Converter describes how generated code calls the converter of one
+// struct pair.
+type Converter struct {
+	// Name is the function name, or the method name for FuncShapeMethod.
+	Name string
+	// Shape is the shape actually generated for the pair.
+	Shape FuncShape
+	// ReturnsError makes calls expect an error result.
+	ReturnsError bool
+	// Params are passed on after the converter's own arguments.
+	Params []Param
+}
+
+// ConverterFor returns the converter generated for srcName -> dstName under
+// shape. Methods can only be declared on non-generic types of the output
+// package, so other pairs fall back to pointer functions.
+func ConverterFor(shape FuncShape, outputPkgPath, srcPkgPath, srcName, dstPkgPath, dstName string, returnsError bool) Converter {
+	c := Converter{
+		Name:         DefaultConverterName(srcPkgPath, srcName, dstPkgPath, dstName),
+		Shape:        shape,
+		ReturnsError: returnsError,
+	}
+	if shape == FuncShapePatch {
+		c.Name = PatchFuncName(dstName)
+		return c
+	}
+	if shape != FuncShapeMethod {
+		return c
+	}
+	if srcPkgPath != outputPkgPath || strings.Contains(srcName, "[") {
+		c.Shape = FuncShapePointer
+		return c
+	}
+	c.Name = MethodName(srcName, dstName)
+	return c
+}
+
+// MethodName returns the converter method name declared on srcName, e.g.
+// ToResponse for User -> UserResponse and ToUserDTO for Account -> UserDTO.
+func MethodName(srcName, dstName string) string {
+	dstName = instanceToken(dstName)
+	if rest, ok := strings.CutPrefix(dstName, srcName); ok && rest != "" && unicode.IsUpper([]rune(rest)[0]) {
+		return "To" + rest
+	}
+	return "To" + dstName
+}
+
+// PatchFuncName returns the name of the function applying patches to
+// dstName, e.g. ApplyUserPatch.
+func PatchFuncName(dstName string) string {
+	return "Apply" + instanceToken(dstName) + "Patch"
+}
+
+// ErrorReturn returns the statement prefix that makes a converter of shape
+// returning dstType fail, e.g. "return nil, " for pointer converters.
+func (s FuncShape) ErrorReturn(dstType string) string {
+	switch s {
+	case FuncShapeValue:
+		return "return " + dstType + "{}, "
+	case FuncShapeInto, FuncShapePatch:
+		return "return "
+	default:
+		return "return nil, "
+	}
+}
+
+// convert calls the converter on src and stores the result in dst. srcPtr
+// reports that src is a pointer, which may be nil, rather than an
+// addressable value; dstPtr likewise for dst, whose element type is dstType.
+// Errors are returned with errorReturn, the ErrorReturn of the calling
+// converter.
+func (c Converter) convert(src string, srcPtr bool, dst target, dstType string, dstPtr bool, errorReturn string) string {
+	switch c.Shape {
+	case FuncShapeValue:
+		return c.convertValue(src, srcPtr, dst, dstType, dstPtr, errorReturn)
+	case FuncShapeInto, FuncShapePatch:
+		return c.convertInto(src, srcPtr, dst, dstType, dstPtr, errorReturn)
+	}
+	call := c.call(pointerArg(src, srcPtr))
+	if dstPtr {
+		if c.ReturnsError {
+			return "if v, err := " + call + "; err != nil {\n" + errorReturn + "err\n} else {\n" + dst.assign("v") + "\n}"
+		}
+		return dst.assign(call)
+	}
+	if c.ReturnsError {
+		return "if v, err := " + call + "; err != nil {\n" + errorReturn + "err\n} else if v != nil {\n" + dst.assign("*v") + "\n}"
+	}
+	return "if v := " + call + "; v != nil {\n" + dst.assign("*v") + "\n}"
+}
+
+func (c Converter) convertValue(src string, srcPtr bool, dst target, dstType string, dstPtr bool, errorReturn string) string {
+	arg := src
+	if srcPtr {
+		arg = "*" + src
+	}
+	var expr string
+	switch {
+	case c.ReturnsError:
+		store := dst.assign("v")
+		if dstPtr {
+			store = dst.assign("&v")
+		}
+		expr = "if v, err := " + c.invoke(arg) + "; err != nil {\n" + errorReturn + "err\n} else {\n" + store + "\n}"
+	case dstPtr:
+		expr = dst.assign("new("+dstType+")") + "\n*" + dst.sel + " = " + c.invoke(arg)
+	default:
+		expr = dst.assign(c.invoke(arg))
+	}
+	if srcPtr {
+		return "if " + src + " != nil {\n" + expr + "\n}"
+	}
+	return expr
+}
+
+func (c Converter) convertInto(src string, srcPtr bool, dst target, dstType string, dstPtr bool, errorReturn string) string {
+	into := "&" + dst.sel
+	var expr string
+	if dstPtr {
+		into = dst.sel
+		expr = "if " + dst.sel + " == nil {\n" + dst.assign("new("+dstType+")") + "\n}\n"
+	} else if dst.then != "" {
+		expr = dst.then + "\n"
+	}
+	expr += c.fill(into, pointerArg(src, srcPtr), errorReturn)
+	if !srcPtr {
+		return expr
+	}
+	expr = "if " + src + " != nil {\n" + expr + "\n}"
+	if dstPtr && c.Shape == FuncShapeInto {
+		// A nil source clears the destination, as assigning a nil result
+		// would; a nil patch leaves it alone.
+		expr += " else {\n" + dst.sel + " = nil\n}"
+	}
+	return expr
+}
+
+// result declares c as a *dstType converted from src, nil when a pointer
+// src is nil.
+func (c Converter) result(src string, srcPtr bool, dstType, errorReturn string) string {
+	switch c.Shape {
+	case FuncShapeValue:
+		if !srcPtr {
+			return c.valueResult(src, ":=", errorReturn)
+		}
+		return "var c *" + dstType + "\nif " + src + " != nil {\n" + c.valueResult("*"+src, "=", errorReturn) + "\n}"
+	case FuncShapeInto, FuncShapePatch:
+		if !srcPtr {
+			return "c := new(" + dstType + ")\n" + c.fill("c", "&"+src, errorReturn)
+		}
+		return "var c *" + dstType + "\nif " + src + " != nil {\nc = new(" + dstType + ")\n" + c.fill("c", src, errorReturn) + "\n}"
+	}
+	call := c.call(pointerArg(src, srcPtr))
+	if c.ReturnsError {
+		return "c, err := " + call + "\nif err != nil {\n" + errorReturn + "err\n}"
+	}
+	return "c := " + call
+}
+
+// valueResult converts arg with a value converter and points c at the result
+// using op, := or =.
+func (c Converter) valueResult(arg, op, errorReturn string) string {
+	if c.ReturnsError {
+		return "r, err := " + c.invoke(arg) + "\nif err != nil {\n" + errorReturn + "err\n}\nc " + op + " &r"
+	}
+	return "r := " + c.invoke(arg) + "\nc " + op + " &r"
+}
+
+// call returns the call expression of a pointer or method converter.
+func (c Converter) call(arg string) string {
+	if c.Shape == FuncShapeMethod {
+		// Methods have pointer receivers, which addressable operands satisfy.
+		return strings.TrimPrefix(arg, "&") + "." + c.invoke()
+	}
+	return c.invoke(arg)
+}
+
+// invoke calls the converter with args followed by the extra parameters.
+func (c Converter) invoke(args ...string) string {
+	return c.Name + "(" + strings.Join(append(args, paramNames(c.Params)...), ", ") + ")"
+}
+
+// fill calls an into converter with a destination and a source pointer.
+func (c Converter) fill(dst, src, errorReturn string) string {
+	call := c.invoke(dst, src)
+	if c.ReturnsError {
+		return "if err := " + call + "; err != nil {\n" + errorReturn + "err\n}"
+	}
+	return call
+}
+
+func pointerArg(src string, srcPtr bool) string {
+	if srcPtr {
+		return src
+	}
+	return "&" + src
+}
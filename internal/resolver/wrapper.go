@@ -0,0 +1,149 @@
+package resolver
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/seitarof/gen-dto/internal/parser"
+)
+
+// Wrapper declares a user-defined optional value type such as
+// optional.Value[T]. Generic wrappers match every instantiation.
+type Wrapper struct {
+	// Type is the package path and type name, e.g. example.com/optional.Value.
+	Type string
+	// Value reads the wrapped value: a field (V) or a method call (Get()).
+	Value string
+	// Valid reports whether a value is present: a field (Set) or a method
+	// call (IsSet()).
+	Valid string
+	// Constructor names a function in the wrapper's package that builds a
+	// present wrapper from a value, e.g. Some. Without it wrappers are built
+	// as composite literals, which requires Value and Valid to be fields.
+	Constructor string
+}
+
+// Validate reports whether w can be used to generate conversions.
+func (w Wrapper) Validate() error {
+	if w.Type == "" || w.Value == "" || w.Valid == "" {
+		return fmt.Errorf("wrapper needs a type, a value and a validity check")
+	}
+	if !strings.Contains(w.Type, ".") {
+		return fmt.Errorf("wrapper type %q must be qualified by its package path", w.Type)
+	}
+	if w.Constructor == "" && (isCall(w.Value) || isCall(w.Valid)) {
+		return fmt.Errorf("wrapper %s reads through methods and needs a constructor", w.Type)
+	}
+	return nil
+}
+
+func isCall(accessor string) bool {
+	return strings.HasSuffix(accessor, "()")
+}
+
+// WrapperRule converts user-defined wrappers to and from their value type,
+// pointers to it, and other wrappers of the same value type, the way
+// NullableRule does for database/sql.
+type WrapperRule struct {
+	Wrappers []Wrapper
+}
+
+func (r *WrapperRule) Name() string { return "wrapper" }
+
+func (r *WrapperRule) Try(src, dst parser.FieldInfo) (ConversionPlan, bool) {
+	if len(r.Wrappers) == 0 {
+		return ConversionPlan{}, false
+	}
+	srcWrapper, srcValue, srcIsWrapper := r.lookup(src.Type)
+	dstWrapper, dstValue, dstIsWrapper := r.lookup(dst.Type)
+	srcSel := srcSelector(src)
+	dstTo := dstTarget(dst)
+
+	switch {
+	case srcIsWrapper && dstIsWrapper:
+		if !isIdenticalType(srcValue, dstValue) {
+			return ConversionPlan{}, false
+		}
+		expr := "if " + srcSel + "." + srcWrapper.Valid + " {\n" +
+			dstTo.assign(dstWrapper.build(dst.TypeStr, srcSel+"."+srcWrapper.Value)) + "\n}"
+		return newPlan(src, dst, StrategyCustomFunc, expr), true
+	case srcIsWrapper:
+		if isIdenticalType(srcValue, dst.Type) {
+			expr := "if " + srcSel + "." + srcWrapper.Valid + " {\n" + dstTo.assign(srcSel+"."+srcWrapper.Value) + "\n}"
+			return newPlan(src, dst, StrategyNullableToValue, expr), true
+		}
+		if elem, ok := pointerElem(dst.Type); ok && isIdenticalType(srcValue, elem) {
+			expr := "if " + srcSel + "." + srcWrapper.Valid + " {\n" +
+				"v := " + srcSel + "." + srcWrapper.Value + "\n" +
+				dstTo.assign("&v") + "\n}"
+			return newPlan(src, dst, StrategyNullableToValue, expr), true
+		}
+	case dstIsWrapper:
+		if isIdenticalType(src.Type, dstValue) {
+			expr := dstTo.assign(dstWrapper.build(dst.TypeStr, srcSel))
+			return newPlan(src, dst, StrategyValueToNullable, expr), true
+		}
+		if elem, ok := pointerElem(src.Type); ok && isIdenticalType(elem, dstValue) {
+			expr := "if " + srcSel + " != nil {\n" + dstTo.assign(dstWrapper.build(dst.TypeStr, "*"+srcSel)) + "\n}"
+			return newPlan(src, dst, StrategyValueToNullable, expr), true
+		}
+	}
+	return ConversionPlan{}, false
+}
+
+// lookup returns the wrapper declared for t and the type it wraps.
+func (r *WrapperRule) lookup(t types.Type) (Wrapper, types.Type, bool) {
+	if t == nil {
+		return Wrapper{}, nil, false
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return Wrapper{}, nil, false
+	}
+	name := named.Obj().Pkg().Path() + "." + named.Obj().Name()
+	for _, w := range r.Wrappers {
+		if w.Type != name {
+			continue
+		}
+		if value, ok := accessorType(named, w.Value); ok {
+			return w, value, true
+		}
+	}
+	return Wrapper{}, nil, false
+}
+
+// accessorType returns the type read by a field or a no-argument method.
+func accessorType(t types.Type, accessor string) (types.Type, bool) {
+	name := strings.TrimSuffix(accessor, "()")
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	switch v := obj.(type) {
+	case *types.Var:
+		return v.Type(), !isCall(accessor)
+	case *types.Func:
+		sig, ok := v.Type().(*types.Signature)
+		if !ok || !isCall(accessor) || sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+			return nil, false
+		}
+		return sig.Results().At(0).Type(), true
+	}
+	return nil, false
+}
+
+// build returns an expression for a present wrapper of typeStr holding value.
+func (w Wrapper) build(typeStr, value string) string {
+	if w.Constructor == "" {
+		return typeStr + "{" + w.Value + ": " + value + ", " + w.Valid + ": true}"
+	}
+	return typeQualifier(typeStr) + w.Constructor + "(" + value + ")"
+}
+
+// typeQualifier returns the package qualifier of a rendered named type, e.g.
+// "optional." for optional.Value[string], or "" for local types.
+func typeQualifier(typeStr string) string {
+	base, _, _ := strings.Cut(typeStr, "[")
+	if i := strings.LastIndex(base, "."); i >= 0 {
+		return base[:i+1]
+	}
+	return ""
+}
@@ -0,0 +1,12 @@
+package dto
+
+type Money struct {
+	Amount int64
+}
+
+type Order struct {
+	ID    string
+	Total Money
+	Tags  []string
+	Note  string
+}
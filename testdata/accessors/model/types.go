@@ -0,0 +1,21 @@
+package model
+
+type Money struct {
+	Amount int64
+}
+
+type Order struct {
+	id    string
+	total Money
+	tags  []string
+	note  *string
+}
+
+func (o *Order) GetID() string      { return o.id }
+func (o *Order) GetTotal() Money    { return o.total }
+func (o *Order) GetTags() []string  { return o.tags }
+func (o *Order) GetNote() *string   { return o.note }
+func (o *Order) SetID(v string)     { o.id = v }
+func (o *Order) SetTotal(v Money)   { o.total = v }
+func (o *Order) SetTags(v []string) { o.tags = v }
+func (o *Order) SetNote(v *string)  { o.note = v }
@@ -0,0 +1,14 @@
+package dto
+
+type Address struct {
+	City string
+}
+
+type UserResponse struct {
+	ID      string
+	Address Address
+}
+
+type UserResponses []*UserResponse
+
+type UserResponsesByID map[string]UserResponse
@@ -0,0 +1,14 @@
+package model
+
+type Address struct {
+	City string
+}
+
+type User struct {
+	ID      string
+	Address Address
+}
+
+type Users []User
+
+type UsersByID map[string]*User
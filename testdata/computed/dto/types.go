@@ -0,0 +1,7 @@
+package dto
+
+type User struct {
+	ID       string
+	FullName string
+	Age      int
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type User struct {
+	ID        string
+	FirstName string
+	LastName  string
+	BirthDate time.Time
+}
@@ -0,0 +1,16 @@
+package dto
+
+type Cell struct {
+	Value int64
+}
+
+type Report struct {
+	Matrix    [][]int64
+	Times     []string
+	Counts    []int64
+	Lookup    map[string]string
+	Grid      [][]Cell
+	ByID      map[int64]Cell
+	MaybeTime string
+	Labels    map[string][]int64
+}
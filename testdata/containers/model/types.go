@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type Cell struct {
+	Value int
+}
+
+type Report struct {
+	Matrix    [][]int
+	Times     []time.Time
+	Counts    *[]int
+	Lookup    map[string]time.Time
+	Grid      [][]Cell
+	ByID      map[int]Cell
+	MaybeTime *time.Time
+	Labels    map[string][]int
+}
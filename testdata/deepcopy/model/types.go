@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/seitarof/gen-dto/testdata/deepcopy/shared"
+)
+
+type Account struct {
+	Tags    []string
+	Scores  map[string][]int
+	Data    []byte
+	Created *time.Time
+	Aliases []*string
+	Nick    string
+	Owner   *shared.Owner
+	Plan    shared.Plan
+}
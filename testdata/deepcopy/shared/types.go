@@ -0,0 +1,11 @@
+package shared
+
+type Owner struct {
+	Name    string
+	Roles   []string
+	Manager *Owner
+}
+
+type Plan struct {
+	Features []string
+}
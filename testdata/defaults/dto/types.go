@@ -0,0 +1,14 @@
+package dto
+
+type Status string
+
+const StatusActive Status = "active"
+
+type User struct {
+	Kind       string
+	APIVersion int
+	ID         string
+	Name       string
+	Status     Status  `dto:"default=StatusActive"`
+	Score      float64 `dto:"default=0.5"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type Record struct {
+	ID        string
+	Title     string
+	CreatedBy string
+	UpdatedBy string
+}
@@ -0,0 +1,12 @@
+package model
+
+type Audit struct {
+	CreatedBy string
+	UpdatedBy string
+}
+
+type Record struct {
+	*Audit
+	ID    string
+	Title string
+}
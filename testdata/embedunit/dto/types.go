@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type Timestamps struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Audit struct {
+	CreatedBy string
+}
+
+type User struct {
+	*Audit
+	ID         string
+	Name       string
+	Timestamps Timestamps
+}
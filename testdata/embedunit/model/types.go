@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type Timestamps struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Audit struct {
+	CreatedBy string
+}
+
+type User struct {
+	Timestamps
+	*Audit
+	ID   string
+	Name string
+}
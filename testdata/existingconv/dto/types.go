@@ -0,0 +1,11 @@
+package dto
+
+type Money struct {
+	Amount   string
+	Currency string
+}
+
+type Order struct {
+	ID    string
+	Total Money
+}
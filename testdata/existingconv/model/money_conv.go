@@ -0,0 +1,17 @@
+package model
+
+import (
+	"strconv"
+
+	"github.com/seitarof/gen-dto/testdata/existingconv/dto"
+)
+
+func ConvertModelMoneyToDtoMoney(src *Money) *dto.Money {
+	if src == nil {
+		return nil
+	}
+	return &dto.Money{
+		Amount:   strconv.FormatInt(src.Amount, 10) + " " + src.Currency,
+		Currency: src.Currency,
+	}
+}
@@ -0,0 +1,11 @@
+package model
+
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+type Order struct {
+	ID    string
+	Total Money
+}
@@ -0,0 +1,10 @@
+package dto
+
+type Customer struct {
+	ID            string
+	Name          string
+	AddressCity   string
+	AddressGeoLat float64
+	BillingCity   string
+	PostalCode    string
+}
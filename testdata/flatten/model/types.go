@@ -0,0 +1,18 @@
+package model
+
+type Customer struct {
+	ID      string
+	Name    string
+	Address *Address
+	Billing Address
+}
+
+type Address struct {
+	City string
+	Zip  string
+	Geo  *Geo
+}
+
+type Geo struct {
+	Lat float64
+}
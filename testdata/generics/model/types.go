@@ -0,0 +1,21 @@
+package model
+
+type User struct {
+	ID   string
+	Name string
+}
+
+type Page[T any] struct {
+	Items []T
+	Total int
+}
+
+type Box[T any] struct {
+	Value T
+}
+
+type Feed struct {
+	Users  Page[User]
+	Latest *Box[User]
+	Count  Box[int]
+}
@@ -0,0 +1,7 @@
+package dto
+
+type UserResponse struct {
+	ID    string
+	Email string
+	Link  string
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/seitarof/gen-dto/testdata/hooks/dto"
+)
+
+func afterConvertUserToUserResponse(src *User, dst *dto.UserResponse) {
+	if at := strings.IndexByte(src.Email, '@'); at > 0 {
+		dst.Email = src.Email[:1] + "***" + src.Email[at:]
+	}
+	dst.Link = "/users/" + src.ID
+}
+
+func beforeConvertUserResponseToUser(src *dto.UserResponse, dst *User) error {
+	if src.ID == "" {
+		return errors.New("missing id")
+	}
+	return nil
+}
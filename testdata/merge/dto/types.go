@@ -0,0 +1,18 @@
+package dto
+
+type Address struct {
+	City string
+	Zip  string
+}
+
+type UserDetailResponse struct {
+	ID        int64
+	Name      string
+	Email     string
+	Bio       string
+	AvatarURL string
+	Followers int64
+	Posts     int
+	Address   Address
+	Activity  string
+}
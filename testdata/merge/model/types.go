@@ -0,0 +1,34 @@
+package model
+
+import "fmt"
+
+type Address struct {
+	City string
+	Zip  string
+}
+
+type User struct {
+	ID      int64
+	Name    string
+	Email   string
+	Address Address
+}
+
+type Profile struct {
+	Name      string
+	Bio       string
+	AvatarURL *string
+}
+
+type Stats struct {
+	Followers int32
+	Posts     int
+}
+
+// Describe summarizes s, which may be nil.
+func (s *Stats) Describe() string {
+	if s == nil {
+		return "inactive"
+	}
+	return fmt.Sprintf("%d posts", s.Posts)
+}
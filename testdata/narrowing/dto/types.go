@@ -0,0 +1,16 @@
+package dto
+
+type Gauge struct {
+	Value int32
+}
+
+type Reading struct {
+	Count  int32
+	Ratio  float32
+	Level  uint8
+	Values []int32
+	Limit  int16
+	Gauge  Gauge
+	Total  int64
+	Sum    float64
+}
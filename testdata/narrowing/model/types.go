@@ -0,0 +1,16 @@
+package model
+
+type Gauge struct {
+	Value float64
+}
+
+type Reading struct {
+	Count  int64
+	Ratio  float64
+	Level  int
+	Values []int64
+	Limit  *int64
+	Gauge  Gauge
+	Total  float64
+	Sum    int64
+}
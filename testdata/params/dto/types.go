@@ -0,0 +1,19 @@
+package dto
+
+type Venue struct {
+	Name string
+	Link string
+}
+
+type Session struct {
+	Title    string
+	StartsAt string
+}
+
+type Event struct {
+	ID       string
+	StartsAt string
+	Local    string
+	Venue    Venue
+	Sessions []Session
+}
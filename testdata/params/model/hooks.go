@@ -0,0 +1,11 @@
+package model
+
+import (
+	"time"
+
+	"github.com/seitarof/gen-dto/testdata/params/dto"
+)
+
+func afterConvertModelVenueToDtoVenue(src *Venue, dst *dto.Venue, loc *time.Location, baseURL string) {
+	dst.Link = baseURL + src.Path
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/seitarof/gen-dto/testdata/params/dto"
+)
+
+func ConvertModelSessionToDtoSession(src *Session, loc *time.Location, baseURL string) *dto.Session {
+	if src == nil {
+		return nil
+	}
+	return &dto.Session{
+		Title:    src.Title,
+		StartsAt: src.StartsAt.In(loc).Format(time.Kitchen),
+	}
+}
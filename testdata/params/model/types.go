@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type Venue struct {
+	Name string
+	Path string
+}
+
+type Session struct {
+	Title    string
+	StartsAt time.Time
+}
+
+type Event struct {
+	ID       string
+	StartsAt time.Time
+	Venue    Venue
+	Sessions []Session
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type AddressPatch struct {
+	City    *string
+	Zip     *string
+	Country string
+}
+
+type UserPatchRequest struct {
+	Name     *string
+	Age      *int64
+	Email    *string
+	Tags     []string
+	Birthday time.Time
+	Address  *AddressPatch
+	Billing  *AddressPatch
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type Address struct {
+	City    string
+	Zip     string
+	Country string
+}
+
+type User struct {
+	ID       string
+	Name     string
+	Age      int32
+	Email    *string
+	Tags     []string
+	Birthday time.Time
+	Address  Address
+	Billing  *Address
+}
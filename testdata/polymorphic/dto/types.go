@@ -0,0 +1,23 @@
+package dto
+
+type PaymentMethodDTO interface {
+	paymentMethod()
+}
+
+type CardDTO struct {
+	Number string
+	Expiry string
+}
+
+func (*CardDTO) paymentMethod() {}
+
+type BankTransferDTO struct {
+	IBAN string
+}
+
+func (BankTransferDTO) paymentMethod() {}
+
+type Order struct {
+	ID      string
+	Payment PaymentMethodDTO
+}
@@ -0,0 +1,29 @@
+package model
+
+type PaymentMethod interface {
+	isPaymentMethod()
+}
+
+type Card struct {
+	Number string
+	Expiry string
+}
+
+func (*Card) isPaymentMethod() {}
+
+type BankTransfer struct {
+	IBAN string
+}
+
+func (BankTransfer) isPaymentMethod() {}
+
+type Voucher struct {
+	Code string
+}
+
+func (Voucher) isPaymentMethod() {}
+
+type Order struct {
+	ID      string
+	Payment PaymentMethod
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+type Event struct {
+	Id         string
+	CreatedAt  time.Time
+	Ttl        time.Duration
+	Title      *string
+	Priority   int64
+	Text       *string
+	Attachment *Attachment
+}
+
+type Attachment struct {
+	Url string
+}
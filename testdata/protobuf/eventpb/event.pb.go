@@ -0,0 +1,51 @@
+// Package eventpb mirrors the shape of a protoc-gen-go message for tests.
+package eventpb
+
+import (
+	"github.com/seitarof/gen-dto/testdata/protobuf/types/known/durationpb"
+	"github.com/seitarof/gen-dto/testdata/protobuf/types/known/timestamppb"
+	"github.com/seitarof/gen-dto/testdata/protobuf/types/known/wrapperspb"
+)
+
+type messageState struct{}
+
+type Event struct {
+	state         messageState
+	sizeCache     int32
+	unknownFields []byte
+
+	Id        string                  `protobuf:"bytes,1,opt,name=id,proto3"`
+	CreatedAt *timestamppb.Timestamp  `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3"`
+	Ttl       *durationpb.Duration    `protobuf:"bytes,3,opt,name=ttl,proto3"`
+	Title     *wrapperspb.StringValue `protobuf:"bytes,4,opt,name=title,proto3"`
+	Priority  *wrapperspb.Int64Value  `protobuf:"bytes,5,opt,name=priority,proto3"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_Text
+	//	*Event_Attachment
+	Payload isEvent_Payload `protobuf_oneof:"payload"`
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_Text struct {
+	Text string `protobuf:"bytes,6,opt,name=text,proto3,oneof"`
+}
+
+type Event_Attachment struct {
+	Attachment *Attachment `protobuf:"bytes,7,opt,name=attachment,proto3,oneof"`
+}
+
+func (*Event_Text) isEvent_Payload() {}
+
+func (*Event_Attachment) isEvent_Payload() {}
+
+type Attachment struct {
+	state         messageState
+	sizeCache     int32
+	unknownFields []byte
+
+	Url string `protobuf:"bytes,1,opt,name=url,proto3"`
+}
@@ -0,0 +1,22 @@
+// Package durationpb mirrors the shape of
+// google.golang.org/protobuf/types/known/durationpb for tests.
+package durationpb
+
+import "time"
+
+type Duration struct {
+	Seconds int64
+	Nanos   int32
+}
+
+func New(d time.Duration) *Duration {
+	nanos := d.Nanoseconds()
+	return &Duration{Seconds: nanos / 1e9, Nanos: int32(nanos % 1e9)}
+}
+
+func (x *Duration) AsDuration() time.Duration {
+	if x == nil {
+		return 0
+	}
+	return time.Duration(x.Seconds)*time.Second + time.Duration(x.Nanos)
+}
@@ -0,0 +1,32 @@
+// Package timestamppb mirrors the shape of
+// google.golang.org/protobuf/types/known/timestamppb for tests.
+package timestamppb
+
+import "time"
+
+type Timestamp struct {
+	Seconds int64
+	Nanos   int32
+}
+
+func New(t time.Time) *Timestamp {
+	return &Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+func (x *Timestamp) GetSeconds() int64 {
+	if x != nil {
+		return x.Seconds
+	}
+	return 0
+}
+
+func (x *Timestamp) GetNanos() int32 {
+	if x != nil {
+		return x.Nanos
+	}
+	return 0
+}
+
+func (x *Timestamp) AsTime() time.Time {
+	return time.Unix(x.GetSeconds(), int64(x.GetNanos())).UTC()
+}
@@ -0,0 +1,29 @@
+// Package wrapperspb mirrors the shape of
+// google.golang.org/protobuf/types/known/wrapperspb for tests.
+package wrapperspb
+
+type StringValue struct {
+	Value string
+}
+
+func String(v string) *StringValue { return &StringValue{Value: v} }
+
+func (x *StringValue) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type Int64Value struct {
+	Value int64
+}
+
+func Int64(v int64) *Int64Value { return &Int64Value{Value: v} }
+
+func (x *Int64Value) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
@@ -0,0 +1,14 @@
+package dto
+
+type Item struct {
+	Name string
+}
+
+type Cart struct {
+	Values    []Item
+	Pointers  []*Item
+	ToValues  []Item
+	ToPointer []*Item
+	Notes     []string
+	Codes     []*string
+}
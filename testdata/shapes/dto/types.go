@@ -0,0 +1,15 @@
+package dto
+
+type Address struct {
+	City string
+}
+
+type UserResponse struct {
+	ID       string
+	Home     Address
+	Work     *Address
+	Billing  Address
+	Shipping *Address
+	Previous []*Address
+	Others   []Address
+}
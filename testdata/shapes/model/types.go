@@ -0,0 +1,15 @@
+package model
+
+type Address struct {
+	City string
+}
+
+type User struct {
+	ID       string
+	Home     Address
+	Work     *Address
+	Billing  *Address
+	Shipping Address
+	Previous []Address
+	Others   []*Address
+}
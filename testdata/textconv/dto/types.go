@@ -0,0 +1,8 @@
+package dto
+
+type Device struct {
+	ID      string
+	Primary string
+	IP      string
+	Tag     string
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+)
+
+type DeviceID struct {
+	value string
+}
+
+func ParseDeviceID(s string) (DeviceID, error) {
+	if !strings.HasPrefix(s, "dev-") {
+		return DeviceID{}, errors.New("invalid device id")
+	}
+	return DeviceID{value: s}, nil
+}
+
+func (id DeviceID) MarshalText() ([]byte, error) {
+	return []byte(id.value), nil
+}
+
+// PrimaryID names DeviceID through an alias.
+type PrimaryID = DeviceID
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+func (t Tag) MarshalText() ([]byte, error) {
+	return []byte(t.Key + "=" + t.Value), nil
+}
+
+func (t *Tag) UnmarshalText(b []byte) error {
+	key, value, ok := strings.Cut(string(b), "=")
+	if !ok {
+		return errors.New("invalid tag")
+	}
+	t.Key, t.Value = key, value
+	return nil
+}
+
+type Device struct {
+	ID      DeviceID
+	Primary PrimaryID
+	IP      netip.Addr
+	Tag     Tag
+}
@@ -0,0 +1,7 @@
+package dto
+
+type Account struct {
+	ID      string
+	Balance int64
+	note    string
+}
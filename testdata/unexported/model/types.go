@@ -0,0 +1,13 @@
+package model
+
+type Account struct {
+	ID      string
+	balance int64
+	note    string
+}
+
+type AccountRecord struct {
+	ID      string
+	balance int64
+	note    string
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/seitarof/gen-dto/testdata/wrappers/optional"
+
+type Profile struct {
+	Nickname *string
+	Age      int
+	Bio      optional.Value[string]
+	Score    *float64
+}
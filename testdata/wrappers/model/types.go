@@ -0,0 +1,10 @@
+package model
+
+import "github.com/seitarof/gen-dto/testdata/wrappers/optional"
+
+type Profile struct {
+	Nickname optional.Value[string]
+	Age      optional.Value[int]
+	Bio      optional.Maybe[string]
+	Score    optional.Maybe[float64]
+}
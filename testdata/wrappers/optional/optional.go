@@ -0,0 +1,25 @@
+package optional
+
+// Value exposes its state through fields.
+type Value[T any] struct {
+	V   T
+	Set bool
+}
+
+// Maybe hides its state behind accessors.
+type Maybe[T any] struct {
+	v  T
+	ok bool
+}
+
+func Of[T any](v T) Maybe[T] {
+	return Maybe[T]{v: v, ok: true}
+}
+
+func (m Maybe[T]) Get() T {
+	return m.v
+}
+
+func (m Maybe[T]) IsPresent() bool {
+	return m.ok
+}